@@ -0,0 +1,201 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+// valueChoice picks values at random, in proportion to their weights
+type valueChoice struct {
+	values  []interface{}
+	weights []int // The running totals of the weights
+	err     error // Set for the enums that couldn't be registered. See RegisterEnum(...)
+}
+
+// newValueChoice creates the choice for the values and their weights.
+// Nil weights give every value the same weight.
+func newValueChoice(values []interface{}, weights []int) (*valueChoice, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("at least one value is needed")
+	}
+
+	c := &valueChoice{values: values, weights: make([]int, len(values))}
+
+	var total int
+	for i := range values {
+		weight := 1
+		if weights != nil {
+			weight = weights[i]
+		}
+		if weight < 0 {
+			return nil, fmt.Errorf("the weight of %#v is negative", values[i])
+		}
+
+		total += weight
+		c.weights[i] = total
+	}
+
+	if total == 0 {
+		return nil, fmt.Errorf("at least one weight must be greater than 0")
+	}
+
+	return c, nil
+}
+
+// fieldValueChoice creates the choice for a field.
+// It returns nil, and records the config error, when the values can't be used
+func (p *Plan) fieldValueChoice(fieldName string, option string, values []interface{}, weights []int) *valueChoice {
+	c, err := newValueChoice(values, weights)
+	if err != nil {
+		p.addConfigError(fieldName, option, fmt.Sprintf("Can't choose the values: %v", err))
+		return nil
+	}
+
+	return c
+}
+
+// weightedValues splits the weights into values and weights.
+// The values are sorted so that the choices can be reproduced with WithSeed(...)
+func weightedValues(weighted map[interface{}]int) ([]interface{}, []int) {
+	values := make([]interface{}, 0, len(weighted))
+	for value := range weighted {
+		values = append(values, value)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return fmt.Sprintf("%#v", values[i]) < fmt.Sprintf("%#v", values[j])
+	})
+
+	weights := make([]int, len(values))
+	for i, value := range values {
+		weights[i] = weighted[value]
+	}
+
+	return values, weights
+}
+
+func (c *valueChoice) pick(rng *rand.Rand) interface{} {
+	n := rng.Intn(c.weights[len(c.weights)-1])
+
+	i := sort.Search(len(c.weights), func(i int) bool { return c.weights[i] > n })
+
+	return c.values[i]
+}
+
+// fits is true when every value can be assigned to t.
+// Primitive values only need to be of the same family of kinds. E.g. "active" fits a `type Status string` field
+func (c *valueChoice) fits(t reflect.Type) bool {
+	for _, value := range c.values {
+		vt := reflect.TypeOf(value)
+		if vt == t {
+			continue
+		}
+
+		if vt == nil || !isPrimitiveKind(vt) || !isPrimitiveKind(t) || kindFamily(vt.Kind()) != kindFamily(t.Kind()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// choiceGenerator returns a generator that picks from the choice using the plan's random source
+func (p *Plan) choiceGenerator(c *valueChoice) GenType {
+	return func() interface{} {
+		return c.pick(p.rng)
+	}
+}
+
+// EnsureOneOf picks the field's values from values
+func (p *Plan) EnsureOneOf(fieldName string, values []interface{}) {
+	if c := p.fieldValueChoice(fieldName, "EnsureOneOf", values, nil); c != nil {
+		p.choiceFields[fieldName] = c
+	}
+}
+
+// EnsureWeighted picks the field's values in proportion to their weights
+func (p *Plan) EnsureWeighted(fieldName string, weighted map[interface{}]int) {
+	values, weights := weightedValues(weighted)
+
+	if c := p.fieldValueChoice(fieldName, "EnsureWeighted", values, weights); c != nil {
+		p.choiceFields[fieldName] = c
+	}
+}
+
+// EnsureMapKeyOneOf picks the map's keys from values
+func (p *Plan) EnsureMapKeyOneOf(fieldName string, values []interface{}) {
+	p.setMapKeyChoice(fieldName, "EnsureMapKeyOneOf", values, nil)
+}
+
+// EnsureMapKeyWeighted picks the map's keys in proportion to their weights
+func (p *Plan) EnsureMapKeyWeighted(fieldName string, weighted map[interface{}]int) {
+	values, weights := weightedValues(weighted)
+
+	p.setMapKeyChoice(fieldName, "EnsureMapKeyWeighted", values, weights)
+}
+
+func (p *Plan) setMapKeyChoice(fieldName string, option string, values []interface{}, weights []int) {
+	if c := p.fieldValueChoice(fieldName, option, values, weights); c != nil {
+		setter := p.ensuredMapFields[fieldName]
+		setter.keyChoice = c
+		setter.keyChoiceOption = option
+
+		p.ensuredMapFields[fieldName] = setter
+	}
+}
+
+// RegisterEnum limits every value of type t generated by the plan to values.
+// An invalid enum is reported when a value of type t is generated, and by Validate()
+func (p *Plan) RegisterEnum(t reflect.Type, values []interface{}) {
+	p.enums[t] = newEnumChoice(t, values)
+}
+
+// newEnumChoice converts the values to t and creates their choice.
+// The choice holds the error when a value isn't a t or there are no values
+func newEnumChoice(t reflect.Type, values []interface{}) *valueChoice {
+	converted := make([]interface{}, len(values))
+
+	for i, value := range values {
+		val := convertValue(reflect.ValueOf(value), t)
+		if !val.IsValid() || val.Type() != t {
+			return &valueChoice{err: fmt.Errorf("The enum value %#v is not a %v", value, t)}
+		}
+
+		converted[i] = val.Interface()
+	}
+
+	c, err := newValueChoice(converted, nil)
+	if err != nil {
+		return &valueChoice{err: fmt.Errorf("Invalid enum: %v", err)}
+	}
+
+	return c
+}
+
+// enumError returns the error of the enum used for the values of type t. Nil when t has no enum or it is valid.
+// It follows the precedence of GetTypeGenerator(...)
+func (p *Plan) enumError(t reflect.Type) error {
+	if p.typeGenerators[t] != nil {
+		return nil
+	}
+	if enum := p.enums[t]; enum != nil {
+		return enum.err
+	}
+	if p.distributions[t] != nil && isNumericType(t) {
+		return nil
+	}
+
+	typeRegistry.RLock()
+	defer typeRegistry.RUnlock()
+
+	if enum := typeRegistry.enums[t]; enum != nil && typeRegistry.generators[t] == nil {
+		return enum.err
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+)
+
+// failingConstraint is implemented by the combinators to report which of their constraints rejected a value
+type failingConstraint interface {
+	failed(field interface{}) FieldConstraint
+}
+
+// failedConstraint returns the constraint that rejected the field's value.
+// Combinators return the sub-constraint that failed, other constraints return themselves.
+func failedConstraint(constraint FieldConstraint, field interface{}) FieldConstraint {
+	if fc, ok := constraint.(failingConstraint); ok {
+		return fc.failed(field)
+	}
+
+	return constraint
+}
+
+type andConstraint struct {
+	constraints []FieldConstraint
+}
+
+// And is valid when all of the constraints are valid.
+//
+// The values are generated by the first constraint that implements GenerativeConstraint.
+// E.g. And(ConstrainIntRange(1, 100), Predicate(isEven, "even"))
+func And(constraints ...FieldConstraint) FieldConstraint {
+	return &andConstraint{constraints: constraints}
+}
+
+func (c *andConstraint) IsValid(field interface{}) bool {
+	for _, constraint := range c.constraints {
+		if !constraint.IsValid(field) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *andConstraint) Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	for _, constraint := range c.constraints {
+		if gc, ok := constraint.(GenerativeConstraint); ok {
+			return gc.Generate(rng, fieldType)
+		}
+	}
+
+	return nil, false
+}
+
+func (c *andConstraint) failed(field interface{}) FieldConstraint {
+	for _, constraint := range c.constraints {
+		if !constraint.IsValid(field) {
+			return failedConstraint(constraint, field)
+		}
+	}
+
+	return nil
+}
+
+func (c *andConstraint) String() string {
+	return fmt.Sprintf("And(%v)", joinConstraints(c.constraints))
+}
+
+type orConstraint struct {
+	constraints []FieldConstraint
+}
+
+// Or is valid when any of the constraints is valid.
+//
+// The values are generated by one of the constraints that implements GenerativeConstraint, picked at random.
+// E.g. Or(ConstrainIntRange(0, 10), ConstrainIntRange(90, 100))
+func Or(constraints ...FieldConstraint) FieldConstraint {
+	return &orConstraint{constraints: constraints}
+}
+
+func (c *orConstraint) IsValid(field interface{}) bool {
+	for _, constraint := range c.constraints {
+		if constraint.IsValid(field) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *orConstraint) Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	var generative []GenerativeConstraint
+	for _, constraint := range c.constraints {
+		if gc, ok := constraint.(GenerativeConstraint); ok {
+			generative = append(generative, gc)
+		}
+	}
+
+	if len(generative) == 0 {
+		return nil, false
+	}
+
+	return generative[rng.Intn(len(generative))].Generate(rng, fieldType)
+}
+
+func (c *orConstraint) String() string {
+	return fmt.Sprintf("Or(%v)", joinConstraints(c.constraints))
+}
+
+type notConstraint struct {
+	constraint FieldConstraint
+}
+
+// Not is valid when the constraint is not valid
+func Not(constraint FieldConstraint) FieldConstraint {
+	return &notConstraint{constraint: constraint}
+}
+
+func (c *notConstraint) IsValid(field interface{}) bool {
+	return !c.constraint.IsValid(field)
+}
+
+func (c *notConstraint) String() string {
+	return fmt.Sprintf("Not(%v)", c.constraint)
+}
+
+type predicateConstraint struct {
+	isValid     func(field interface{}) bool
+	description string
+}
+
+// Predicate creates a constraint from a function. The description is used in the error messages.
+// E.g. Predicate(func(v interface{}) bool { return v.(int)%2 == 0 }, "even")
+func Predicate(isValid func(field interface{}) bool, description string) FieldConstraint {
+	return &predicateConstraint{isValid: isValid, description: description}
+}
+
+func (c *predicateConstraint) IsValid(field interface{}) bool {
+	return c.isValid(field)
+}
+
+func (c *predicateConstraint) String() string {
+	return c.description
+}
+
+func joinConstraints(constraints []FieldConstraint) string {
+	names := make([]string, len(constraints))
+	for i, constraint := range constraints {
+		names[i] = fmt.Sprint(constraint)
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,377 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVEncoding controls how slices, arrays, maps and pointers are written by WriteCSV(...)
+type CSVEncoding int
+
+const (
+	// CSVDefault writes slices, arrays and maps as JSON cells, and pointers as the columns of their value
+	CSVDefault CSVEncoding = iota
+	// CSVJSON writes the value as a JSON cell
+	CSVJSON
+	// CSVColumns writes a column per element. E.g. Tags[0], Tags[1] for slices and Scores[math] for maps.
+	// Pointers get the columns of their value
+	CSVColumns
+	// CSVSkip leaves out the value's columns
+	CSVSkip
+)
+
+// CSVOptions configures WriteCSV(...). The zero value writes every column with the default encodings.
+type CSVOptions struct {
+	Slices   CSVEncoding // Slices and arrays
+	Maps     CSVEncoding
+	Pointers CSVEncoding
+
+	// Headers renames the columns. E.g. {"Address.Street": "street"}
+	Headers map[string]string
+
+	// Columns selects the columns to write and their order. A struct's path selects all of its columns.
+	// E.g. []string{"ID", "Address"} writes ID followed by the columns of Address
+	Columns []string
+
+	// Comma is the field delimiter. It defaults to ','
+	Comma rune
+}
+
+// elementIndexPattern matches the element indexes and map keys of a column path. E.g. [0] in Tags[0]
+var elementIndexPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// csvColumn is a column of the CSV and the cell of each item
+type csvColumn struct {
+	path  string // The qualified field path. E.g. Address.Street or Tags[0]
+	cells []string
+}
+
+// csvWriter flattens the generated items into columns
+type csvWriter struct {
+	plan    *Plan
+	opts    CSVOptions
+	columns []*csvColumn
+	err     error // The first cell that couldn't be formatted
+}
+
+// writeCSV writes the items to w as CSV with a header row. See Factory.WriteCSV(...)
+func (p *Plan) writeCSV(w io.Writer, rootType reflect.Type, items []interface{}, opts CSVOptions) error {
+	for rootType.Kind() == reflect.Ptr {
+		rootType = rootType.Elem()
+	}
+
+	values := make([]reflect.Value, len(items))
+	for i, item := range items {
+		values[i] = reflect.ValueOf(item)
+	}
+
+	cw := &csvWriter{plan: p, opts: opts}
+	cw.addColumns(rootType, "", values, 0)
+	if cw.err != nil {
+		return cw.err
+	}
+
+	columns, err := cw.selectColumns()
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if opts.Comma != 0 {
+		writer.Comma = opts.Comma
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = column.path
+		if name, ok := opts.Headers[column.path]; ok {
+			header[i] = name
+		}
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for itemIndex := range items {
+		for i, column := range columns {
+			row[i] = column.cells[itemIndex]
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// addColumns adds the columns of the values at path. The values are invalid for the items that don't have the path.
+// E.g. a nil pointer or a slice without the element.
+func (cw *csvWriter) addColumns(t reflect.Type, path string, values []reflect.Value, depth int) {
+	if depth > maxFieldPathDepth {
+		return // Recursive types
+	}
+
+	switch {
+	case t == timeType || isPrimitiveKind(t):
+		cw.addColumn(path, values, formatCell)
+
+	case t.Kind() == reflect.Struct:
+		cw.addStructColumns(t, path, values, depth)
+
+	case t.Kind() == reflect.Ptr:
+		switch cw.opts.Pointers {
+		case CSVSkip:
+		case CSVJSON:
+			cw.addColumn(path, values, jsonCell)
+		default:
+			cw.addColumns(t.Elem(), path, mapValues(values, reflect.Value.Elem), depth+1)
+		}
+
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		switch cw.opts.Slices {
+		case CSVSkip:
+		case CSVColumns:
+			for i := 0; i < maxLen(values); i++ {
+				elements := mapValues(values, func(v reflect.Value) reflect.Value {
+					if i >= v.Len() {
+						return reflect.Value{}
+					}
+					return v.Index(i)
+				})
+
+				cw.addColumns(t.Elem(), arrayElementName(path, i), elements, depth+1)
+			}
+		default:
+			cw.addColumn(path, values, jsonCell)
+		}
+
+	case t.Kind() == reflect.Map:
+		switch cw.opts.Maps {
+		case CSVSkip:
+		case CSVColumns:
+			for _, key := range mapKeys(values) {
+				elements := mapValues(values, func(v reflect.Value) reflect.Value {
+					return v.MapIndex(key)
+				})
+
+				cw.addColumns(t.Elem(), fmt.Sprintf("%s[%v]", path, key), elements, depth+1)
+			}
+		default:
+			cw.addColumn(path, values, jsonCell)
+		}
+
+	default: // E.g. interface fields
+		cw.addColumn(path, values, jsonCell)
+	}
+}
+
+// addStructColumns adds the columns of the struct's exported fields that weren't omitted
+func (cw *csvWriter) addStructColumns(t reflect.Type, path string, values []reflect.Value, depth int) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // Skip private fields
+		}
+
+		qualifiedName := distinctFileName(path, field.Name)
+		if cw.plan.isOmittedField(field, qualifiedName) {
+			continue
+		}
+
+		fields := mapValues(values, func(v reflect.Value) reflect.Value {
+			return v.Field(i)
+		})
+
+		cw.addColumns(field.Type, qualifiedName, fields, depth+1)
+	}
+}
+
+// isOmittedField is true for the fields that the plan doesn't generate. See Omit(...) and the omit tag directive.
+// The element indexes are removed from the path since the fields of slice elements are configured without them.
+func (p *Plan) isOmittedField(field reflect.StructField, qualifiedName string) bool {
+	configName := elementIndexPattern.ReplaceAllString(qualifiedName, "")
+	if p.omittedFields[qualifiedName] || p.omittedFields[configName] {
+		return true
+	}
+
+	rule := p.fieldTagRule(field, configName)
+
+	return rule != nil && rule.omit && !p.hasFieldOption(configName)
+}
+
+func (cw *csvWriter) addColumn(path string, values []reflect.Value, format func(reflect.Value) (string, error)) {
+	column := &csvColumn{path: path, cells: make([]string, len(values))}
+	for i, v := range values {
+		if !v.IsValid() {
+			continue
+		}
+
+		cell, err := format(v)
+		if err != nil && cw.err == nil {
+			cw.err = fmt.Errorf("can't write the CSV column '%v' of item %v: %w", path, i, err)
+		}
+		column.cells[i] = cell
+	}
+
+	cw.columns = append(cw.columns, column)
+}
+
+// selectColumns orders the columns using CSVOptions.Columns
+func (cw *csvWriter) selectColumns() ([]*csvColumn, error) {
+	if len(cw.opts.Columns) == 0 {
+		return cw.columns, nil
+	}
+
+	var selected []*csvColumn
+	for _, name := range cw.opts.Columns {
+		found := false
+		for _, column := range cw.columns {
+			if column.path == name || strings.HasPrefix(column.path, name+".") || strings.HasPrefix(column.path, name+"[") {
+				selected = append(selected, column)
+				found = true
+			}
+		}
+
+		if !found {
+			var paths []string // The columns and the structs that hold them. E.g. Address.Street and Address
+			for _, column := range cw.columns {
+				for i, r := range column.path {
+					if r == '.' || r == '[' {
+						paths = append(paths, column.path[:i])
+					}
+				}
+				paths = append(paths, column.path)
+			}
+
+			return nil, &FieldPathError{FieldName: name, Reason: "There is no CSV column for the field", Suggestion: closestFieldPath(name, paths)}
+		}
+	}
+
+	return selected, nil
+}
+
+// formatCell formats primitive values and times. It has the signature of jsonCell(...) but never fails
+func formatCell(v reflect.Value) (string, error) {
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10), nil
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case v.CanFloat():
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	}
+
+	return v.String(), nil
+}
+
+// jsonCell formats the value as JSON. Nil values are empty cells.
+// The error is from encoding/json. E.g. for a map[bool]int
+func jsonCell(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		if v.IsNil() {
+			return "", nil
+		}
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// mapValues applies fn to the valid values. Nil pointers, slices and maps become invalid values
+func mapValues(values []reflect.Value, fn func(reflect.Value) reflect.Value) []reflect.Value {
+	result := make([]reflect.Value, len(values))
+	for i, v := range values {
+		if !v.IsValid() {
+			continue
+		}
+
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			if v.IsNil() {
+				continue
+			}
+		}
+
+		result[i] = fn(v)
+	}
+
+	return result
+}
+
+// maxLen returns the length of the longest slice or array
+func maxLen(values []reflect.Value) int {
+	var n int
+	for _, v := range values {
+		if v.IsValid() && v.Len() > n {
+			n = v.Len()
+		}
+	}
+
+	return n
+}
+
+// mapKeys returns the keys of all the maps in order. See lessKey(...)
+func mapKeys(values []reflect.Value) []reflect.Value {
+	seen := make(map[string]bool)
+
+	var keys []reflect.Value
+	for _, v := range values {
+		if !v.IsValid() {
+			continue
+		}
+
+		for _, key := range v.MapKeys() {
+			if name := fmt.Sprint(key); !seen[name] {
+				seen[name] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+
+	return keys
+}
+
+// lessKey orders numeric keys by their value and the other keys by their formatted value
+func lessKey(a reflect.Value, b reflect.Value) bool {
+	switch {
+	case a.CanInt():
+		return a.Int() < b.Int()
+	case a.CanUint():
+		return a.Uint() < b.Uint()
+	case a.CanFloat():
+		return a.Float() < b.Float()
+	}
+
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
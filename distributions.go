@@ -0,0 +1,251 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync"
+)
+
+// poissonNormalThreshold is the mean above which Poisson(...) uses the normal approximation
+const poissonNormalThreshold = 30
+
+// Distribution samples the values of numeric fields. See EnsureDistribution(...)
+//
+// The samples are rounded to the nearest whole number for integer fields, and clamped to the values the field's kind can hold.
+// E.g. negative samples are 0 for unsigned fields.
+type Distribution interface {
+	Sample(rng *rand.Rand) float64
+}
+
+type normalDistribution struct {
+	mean   float64
+	stdDev float64
+}
+
+// Normal is the normal (Gaussian) distribution. E.g. Normal(100, 15) for scores centred on 100
+//
+// A *DistributionError is returned unless stdDev >= 0.
+func Normal(mean float64, stdDev float64) (Distribution, error) {
+	if stdDev < 0 {
+		return nil, &DistributionError{Distribution: "Normal", Reason: fmt.Sprintf("requires stdDev >= 0, got stdDev=%v", stdDev)}
+	}
+
+	return &normalDistribution{mean: mean, stdDev: stdDev}, nil
+}
+
+func (d *normalDistribution) Sample(rng *rand.Rand) float64 {
+	return d.mean + rng.NormFloat64()*d.stdDev
+}
+
+func (d *normalDistribution) String() string {
+	return fmt.Sprintf("Normal(%v, %v)", d.mean, d.stdDev)
+}
+
+type logNormalDistribution struct {
+	mu    float64
+	sigma float64
+}
+
+// LogNormal is the distribution of a value whose logarithm is Normal(mu, sigma).
+// It gives the long tail of order amounts and latencies. The median is e^mu.
+//
+// A *DistributionError is returned unless sigma >= 0.
+func LogNormal(mu float64, sigma float64) (Distribution, error) {
+	if sigma < 0 {
+		return nil, &DistributionError{Distribution: "LogNormal", Reason: fmt.Sprintf("requires sigma >= 0, got sigma=%v", sigma)}
+	}
+
+	return &logNormalDistribution{mu: mu, sigma: sigma}, nil
+}
+
+func (d *logNormalDistribution) Sample(rng *rand.Rand) float64 {
+	return math.Exp(d.mu + rng.NormFloat64()*d.sigma)
+}
+
+func (d *logNormalDistribution) String() string {
+	return fmt.Sprintf("LogNormal(%v, %v)", d.mu, d.sigma)
+}
+
+type exponentialDistribution struct {
+	rate float64
+}
+
+// Exponential is the distribution of the time between events that occur at rate. The mean is 1/rate
+//
+// A *DistributionError is returned unless rate > 0.
+func Exponential(rate float64) (Distribution, error) {
+	if rate <= 0 {
+		return nil, &DistributionError{Distribution: "Exponential", Reason: fmt.Sprintf("requires rate > 0, got rate=%v", rate)}
+	}
+
+	return &exponentialDistribution{rate: rate}, nil
+}
+
+func (d *exponentialDistribution) Sample(rng *rand.Rand) float64 {
+	return rng.ExpFloat64() / d.rate
+}
+
+func (d *exponentialDistribution) String() string {
+	return fmt.Sprintf("Exponential(%v)", d.rate)
+}
+
+type zipfDistribution struct {
+	s    float64
+	v    float64
+	imax uint64
+
+	mu   sync.Mutex // Guards the cached generator since a distribution can be shared by factories
+	rng  *rand.Rand
+	zipf *rand.Zipf // Built for rng on first use. The plan's random source is replaced for each run
+}
+
+// Zipf is the distribution of ranks in [0, imax] where the probability of k is proportional to (v + k) ** (-s).
+// Small values are the most common. E.g. the popularity of products.
+//
+// A *DistributionError is returned unless s > 1 and v >= 1.
+func Zipf(s float64, v float64, imax uint64) (Distribution, error) {
+	if s <= 1 || v < 1 {
+		return nil, &DistributionError{Distribution: "Zipf", Reason: fmt.Sprintf("requires s > 1 and v >= 1, got s=%v v=%v", s, v)}
+	}
+
+	return &zipfDistribution{s: s, v: v, imax: imax}, nil
+}
+
+func (d *zipfDistribution) Sample(rng *rand.Rand) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.rng != rng {
+		d.rng = rng
+		d.zipf = rand.NewZipf(rng, d.s, d.v, d.imax)
+	}
+
+	return float64(d.zipf.Uint64())
+}
+
+func (d *zipfDistribution) String() string {
+	return fmt.Sprintf("Zipf(%v, %v, %v)", d.s, d.v, d.imax)
+}
+
+type poissonDistribution struct {
+	mean float64
+}
+
+// Poisson is the distribution of the number of events in an interval when mean events are expected. E.g. items per order
+//
+// A *DistributionError is returned unless mean >= 0.
+func Poisson(mean float64) (Distribution, error) {
+	if mean < 0 {
+		return nil, &DistributionError{Distribution: "Poisson", Reason: fmt.Sprintf("requires mean >= 0, got mean=%v", mean)}
+	}
+
+	return &poissonDistribution{mean: mean}, nil
+}
+
+func (d *poissonDistribution) Sample(rng *rand.Rand) float64 {
+	if d.mean > poissonNormalThreshold {
+		return math.Max(0, math.Round(d.mean+rng.NormFloat64()*math.Sqrt(d.mean)))
+	}
+
+	// Knuth's algorithm multiplies uniform samples until they drop below e^-mean
+	limit, product := math.Exp(-d.mean), rng.Float64()
+
+	var k float64
+	for ; product > limit; k++ {
+		product *= rng.Float64()
+	}
+
+	return k
+}
+
+func (d *poissonDistribution) String() string {
+	return fmt.Sprintf("Poisson(%v)", d.mean)
+}
+
+type uniformDistribution struct {
+	min float64
+	max float64
+}
+
+// Uniform is the uniform distribution over [min, max)
+//
+// A *DistributionError is returned unless max >= min.
+func Uniform(min float64, max float64) (Distribution, error) {
+	if max < min {
+		return nil, &DistributionError{Distribution: "Uniform", Reason: fmt.Sprintf("requires max >= min, got min=%v max=%v", min, max)}
+	}
+
+	return &uniformDistribution{min: min, max: max}, nil
+}
+
+func (d *uniformDistribution) Sample(rng *rand.Rand) float64 {
+	return d.min + rng.Float64()*(d.max-d.min)
+}
+
+func (d *uniformDistribution) String() string {
+	return fmt.Sprintf("Uniform(%v, %v)", d.min, d.max)
+}
+
+// EnsureDistribution samples the field's values from the distribution
+func (p *Plan) EnsureDistribution(fieldName string, dist Distribution) {
+	p.distributionFields[fieldName] = dist
+}
+
+// RegisterDistribution samples every value of the numeric type t generated by the plan from the distribution
+func (p *Plan) RegisterDistribution(t reflect.Type, dist Distribution) {
+	p.distributions[t] = dist
+}
+
+// distributionGenerator returns a generator that samples values of fieldType using the plan's random source
+func (p *Plan) distributionGenerator(dist Distribution, fieldType reflect.Type) GenType {
+	return func() interface{} {
+		return sampleValue(dist.Sample(p.rng), fieldType)
+	}
+}
+
+// sampleValue converts the sample to the numeric fieldType. See Distribution
+func sampleValue(sample float64, fieldType reflect.Type) interface{} {
+	val := reflect.New(fieldType).Elem()
+
+	switch {
+	case val.CanInt():
+		min, max := intBounds(fieldType)
+		switch n := math.Round(sample); {
+		case n <= float64(min) || math.IsNaN(n):
+			val.SetInt(min)
+		case n >= float64(max):
+			val.SetInt(max)
+		default:
+			val.SetInt(int64(n))
+		}
+
+	case val.CanUint():
+		max := uint64(math.MaxUint64 >> (64 - fieldType.Bits()))
+		switch n := math.Round(sample); {
+		case n <= 0 || math.IsNaN(n):
+			val.SetUint(0)
+		case n >= float64(max):
+			val.SetUint(max)
+		default:
+			val.SetUint(uint64(n))
+		}
+
+	case fieldType.Kind() == reflect.Float32:
+		val.SetFloat(math.Max(-math.MaxFloat32, math.Min(sample, math.MaxFloat32)))
+
+	default:
+		val.SetFloat(sample)
+	}
+
+	return val.Interface()
+}
+
+// isNumericType is true for the integer and float kinds, including named types
+func isNumericType(t reflect.Type) bool {
+	return isPrimitiveKind(t) && kindFamily(t.Kind()) == reflect.Float64
+}
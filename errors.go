@@ -0,0 +1,123 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ConstraintError is returned when a field value does not meet its FieldConstraint
+type ConstraintError struct {
+	FieldName  string // The qualified field name e.g. Car.Engine.SerialNumber. Empty for the constraints set with EnsureItem(...)
+	ItemIndex  int
+	Constraint FieldConstraint
+	Failed     FieldConstraint // The sub-constraint that rejected the last value. E.g. the failed constraint of an And(...)
+	Attempts   int             // The number of generated values that were tried. 0 when the value came from an Ensure(...)
+}
+
+func (e *ConstraintError) Error() string {
+	var failed string
+	if e.Failed != nil && e.Failed != e.Constraint {
+		failed = fmt.Sprintf(". Failed: %v", e.Failed)
+	}
+
+	if e.Attempts == 0 {
+		return fmt.Sprintf("Constraint clashes with one of your Ensure methods. Invalid FieldConstraint for field '%v' (item %v). Constraint: %#v%v.", e.FieldName, e.ItemIndex, e.Constraint, failed)
+	}
+
+	if e.FieldName == "" {
+		return fmt.Sprintf("Unable to meet constraint %v for item %v after '%v' tries", e.Constraint, e.ItemIndex, e.Attempts)
+	}
+
+	return fmt.Sprintf("Unable to meet constraint %v for field '%v' (item %v) after '%v' tries%v", e.Constraint, e.FieldName, e.ItemIndex, e.Attempts, failed)
+}
+
+// UnsupportedKindError is returned when salem doesn't know how to generate a value for a type
+type UnsupportedKindError struct {
+	FieldName string // The qualified field name e.g. Car.Engine.SerialNumber
+	ItemIndex int
+	Type      reflect.Type
+	Reason    string
+}
+
+func (e *UnsupportedKindError) Error() string {
+	return fmt.Sprintf("%v. Field: '%v' (item %v) type: %v kind: %v", e.Reason, e.FieldName, e.ItemIndex, e.Type, e.Type.Kind())
+}
+
+// FieldPathError is returned when a field path can't be used with the requested configuration
+type FieldPathError struct {
+	FieldName  string // The qualified field name e.g. Car.Engine.SerialNumber
+	Reason     string
+	Suggestion string // The closest valid field path. Empty when there is no close match
+}
+
+func (e *FieldPathError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("%v. Field: '%v'. Did you mean '%v'?", e.Reason, e.FieldName, e.Suggestion)
+	}
+
+	return fmt.Sprintf("%v. Field: '%v'", e.Reason, e.FieldName)
+}
+
+// UnboundedError is returned when the items of a factory set with WithUnboundedItems() are collected. E.g. by ExecuteE()
+type UnboundedError struct {
+	Reason string
+}
+
+func (e *UnboundedError) Error() string {
+	return e.Reason
+}
+
+// DistributionError is returned when a Distribution can't be created with the given parameters. E.g. Uniform(10, 1)
+type DistributionError struct {
+	Distribution string
+	Reason       string
+}
+
+func (e *DistributionError) Error() string {
+	return fmt.Sprintf("Invalid %v distribution: %v", e.Distribution, e.Reason)
+}
+
+// ValidationError is returned by Factory.Validate() and holds an error for each invalid field path
+type ValidationError struct {
+	Errors []*FieldPathError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("Invalid factory configuration:\n%v", strings.Join(msgs, "\n"))
+}
+
+// Unwrap allows errors.As(...) to find the individual *FieldPathError
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+// recoverError converts the salem errors raised while generating mocks into an error.
+// Any other panic is passed on.
+func recoverError(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	switch e := r.(type) {
+	case *ConstraintError, *UnsupportedKindError, *FieldPathError, *UnboundedError:
+		*err = e.(error)
+
+	default:
+		panic(r)
+	}
+}
@@ -4,16 +4,20 @@
 package salem
 
 import (
+	"io"
 	"math/rand"
 	"reflect"
+	"regexp"
+	"time"
 )
 
 type nilStruct struct {
 }
 
 type Factory struct {
-	rootType interface{}
-	plan     *Plan
+	rootType  interface{}
+	plan      *Plan
+	streamErr error // The error that stopped the most recent Stream(...) or All()
 }
 
 // Tap creates a factory based on the public fields type.
@@ -28,9 +32,93 @@ func (f *Factory) GetPlan() *Plan {
 	return f.plan
 }
 
-// Execute execute the factory instructions to generate the mocks
+// Execute execute the factory instructions to generate the mocks.
+//
+// Execute panics when the mocks can't be generated. Use ExecuteE to get the error instead.
 func (f *Factory) Execute() []interface{} {
-	return f.plan.Run(f)
+	results, err := f.ExecuteE()
+	if err != nil {
+		panic(err)
+	}
+
+	return results
+}
+
+// ExecuteE execute the factory instructions to generate the mocks.
+//
+// The error is one of *ConstraintError, *UnsupportedKindError, *FieldPathError or *UnboundedError.
+func (f *Factory) ExecuteE() (results []interface{}, err error) {
+	defer recoverError(&err)
+
+	return f.plan.Run(f), nil
+}
+
+// WriteCSV generates the mocks and writes them to w as CSV with a header row.
+//
+// Nested struct fields are flattened into columns named by their field path. E.g. Address.Street.
+// Slices and maps are JSON cells by default. Use CSVColumns to get a column per element instead. E.g. Tags[0], Tags[1].
+// Omitted fields don't have columns. See CSVOptions to rename, order and select the columns.
+// The error is from w, encoding/json or one of the ExecuteE errors. E.g. a map[bool]int field can't be a JSON cell.
+func (f *Factory) WriteCSV(w io.Writer, opts CSVOptions) error {
+	items, err := f.ExecuteE()
+	if err != nil {
+		return err
+	}
+
+	return f.plan.writeCSV(w, reflect.TypeOf(f.rootType), items, opts)
+}
+
+// WriteJSON generates the mocks and writes them to w as a JSON array.
+//
+// The items are written as they are generated, so the mocks aren't held in memory. The json struct tags,
+// including omitempty, are respected. See JSONOptions to indent the JSON.
+// The error is from w, encoding/json or one of the ExecuteE errors.
+func (f *Factory) WriteJSON(w io.Writer, opts JSONOptions) (err error) {
+	defer recoverError(&err)
+
+	return f.plan.writeJSON(f, w, opts)
+}
+
+// WriteJSONLines generates the mocks and writes each one to w as a line of JSON (JSON Lines). See WriteJSON(...)
+func (f *Factory) WriteJSONLines(w io.Writer) (err error) {
+	defer recoverError(&err)
+
+	return f.plan.writeJSONLines(f, w)
+}
+
+// WriteSQL generates the mocks and writes them to w as INSERT statements for the table.
+// E.g. WriteSQL(w, "public.users", salem.Postgres, salem.SQLBatchSize(500))
+//
+// The columns are named by the db or gorm column tags, or the snake_cased field names. Fields tagged with "-" and omitted fields are skipped.
+// Nested structs are flattened into prefixed columns (e.g. address_street) unless SQLNestedJSON() is used.
+// Nil pointers are NULL, times are UTC timestamps, and slices and maps are JSON strings.
+// The rows are streamed in batches of DefaultSQLBatchSize unless SQLBatchSize(...) is used.
+// The error is from w, encoding/json or one of the ExecuteE errors. E.g. a map[bool]int field can't be a JSON string.
+func (f *Factory) WriteSQL(w io.Writer, table string, dialect SQLDialect, opts ...SQLOption) (err error) {
+	defer recoverError(&err)
+
+	return f.plan.writeSQL(f, w, table, dialect, opts)
+}
+
+// WriteGoLiteral generates the mocks and writes them to w as a gofmt'd Go variable. E.g.
+//
+//	var people = []examples.Person{
+//		{
+//			FName: "BDMHKCTVZMER",
+//			Age:   26,
+//		},
+//	}
+//
+// This freezes the mocks into fixtures for _test.go files. The zero valued fields are left out, times are calls to time.Date(...)
+// and pointers to primitives use a generic helper that is written after the variable. E.g. peoplePtr[string]("text").
+// The types are qualified by their package name unless GoLiteralPackage(...) is used. The file needs to import the packages.
+func (f *Factory) WriteGoLiteral(w io.Writer, varName string, opts ...GoLiteralOption) error {
+	items, err := f.ExecuteE()
+	if err != nil {
+		return err
+	}
+
+	return f.plan.writeGoLiteral(w, reflect.TypeOf(f.rootType), items, varName, opts)
 }
 
 // ExecuteToType returns a slice that tis the same type as the Mock's parameter.
@@ -68,6 +156,32 @@ func (f *Factory) ExecuteToType() interface{} {
 	return slice.Interface()
 }
 
+// WithSeed fixes the seed of the random source used to generate the mocks.
+//
+// Executing factories with the same seed and configuration generates the same mocks.
+func (f *Factory) WithSeed(seed int64) *Factory {
+	f.plan.SetSeed(seed)
+
+	return f
+}
+
+// Seed returns the seed used by the most recent Execute.
+//
+// When WithSeed(...) was not called a new seed is picked for each Execute.
+// Pass the reported seed to WithSeed(...) to replay a failing run.
+func (f *Factory) Seed() int64 {
+	return f.plan.GetSeed()
+}
+
+// Validate checks the field paths used to configure the factory against the mocked type.
+//
+// Every path that doesn't resolve to an exported field, or that points at the wrong kind of
+// field (e.g. WithExactMapItems(...) on a field that isn't a map), is reported in a *ValidationError.
+// Paths configured on tapped factories are also checked, as are the salem struct tags of the mocked type.
+func (f *Factory) Validate() error {
+	return f.plan.Validate(reflect.TypeOf(f.rootType))
+}
+
 // Omit fields with the specifed name
 func (f *Factory) Omit(fieldName string) *Factory {
 	f.plan.OmitField(fieldName)
@@ -90,20 +204,65 @@ func (f *Factory) Ensure(fieldName string, sharedValue interface{}) *Factory {
 }
 
 // EnsureConstraint set a constraint that limits the generated value.
+// Calling EnsureConstraint(...) again for the same field adds the constraint, see And(...).
 //
-// The constraint panics if there is an f.Ensure(...) which generates a value resulting in a false constraint
+// The constraint fails if there is an f.Ensure(...) which generates a value resulting in a false constraint
 //
 // Alternatively, the method also fails after trying to generate a constraint after
-// several attempts.
+// several attempts. In both cases Execute panics and ExecuteE returns a *ConstraintError.
 //
 // The default attempts is defined by SuggestedConstraintRetryAttempts.
 // Use f.GetPlan().SetMaxConstraintsRetryAttempts(...) the change the number of retry attempts.
+//
+// Constraints that implement GenerativeConstraint generate a valid value directly, without any retries.
 func (f *Factory) EnsureConstraint(fieldName string, constraint FieldConstraint) *Factory {
 	f.plan.EnsuredFieldValueConstraint(fieldName, constraint)
 
 	return f
 }
 
+// EnsureItem sets a constraint that each generated item must meet. E.g. EndDate after StartDate.
+//
+// Items that don't meet the constraint are generated again, using the same retry attempts as EnsureConstraint(...).
+// Calling EnsureItem(...) again adds another constraint. Use EnsureDerived(...) for fields that can be computed.
+func (f *Factory) EnsureItem(isValid func(item interface{}) bool) *Factory {
+	f.plan.AddItemConstraint(isValid)
+
+	return f
+}
+
+// EnsureDerived computes the value of a field from the rest of the generated item.
+// E.g. EnsureDerived("Total", func(item interface{}) interface{} { o := item.(order); return o.Qty * o.UnitPrice })
+//
+// The fields are derived before the EnsureItem(...) constraints are checked and in the order they were set.
+// Returning nil sets the field to its zero value.
+func (f *Factory) EnsureDerived(fieldName string, derive func(item interface{}) interface{}) *Factory {
+	f.plan.SetDerivedField(fieldName, derive)
+
+	return f
+}
+
+// EnsureUnique makes the values of the fields unique across the items of an execution.
+// When several fields are given their combination of values is unique. E.g. EnsureUnique("FName", "LName")
+//
+// Items with values that were already generated are generated again, using the same retry attempts as
+// EnsureConstraint(...). Execute panics and ExecuteE returns a *ConstraintError when the values run out.
+//
+// The generated keys of map fields are always unique.
+func (f *Factory) EnsureUnique(fieldNames ...string) *Factory {
+	f.plan.AddUniqueFields(fieldNames, false)
+
+	return f
+}
+
+// EnsureUniqueAcrossExecutions makes the values of the fields unique across every execution of the factory.
+// See EnsureUnique(...)
+func (f *Factory) EnsureUniqueAcrossExecutions(fieldNames ...string) *Factory {
+	f.plan.AddUniqueFields(fieldNames, true)
+
+	return f
+}
+
 // EnsureSequence is used to specify the actual values for the fields.
 // The sequence items are based their item index in the overall item list.
 // The values default to their empty value if the items exceed the number of squence items.
@@ -122,6 +281,86 @@ func (f *Factory) EnsureSequenceAcross(fieldName string, seq ...interface{}) *Fa
 	return f
 }
 
+// EnsureOneOf picks the field's values at random from values. E.g. EnsureOneOf("Status", "active", "suspended", "closed")
+//
+// The choice applies to the values the field holds, so pointers and the elements of slices, arrays and maps are picked from values.
+// Primitive values are converted to the field's type. E.g. "active" for a `type Status string` field.
+// Validate() and ExecuteE return a *FieldPathError when there are no values or they don't fit the field.
+// EnsureOneOf takes precedence over EnsurePattern(...) and the field's tags.
+func (f *Factory) EnsureOneOf(fieldName string, values ...interface{}) *Factory {
+	f.plan.EnsureOneOf(fieldName, values)
+
+	return f
+}
+
+// EnsureWeighted picks the field's values at random in proportion to their weights.
+// E.g. EnsureWeighted("Status", map[interface{}]int{"active": 80, "suspended": 15, "closed": 5})
+//
+// Values with a weight of 0 aren't picked, and negative weights are reported like the values that don't fit. See EnsureOneOf(...)
+func (f *Factory) EnsureWeighted(fieldName string, weighted map[interface{}]int) *Factory {
+	f.plan.EnsureWeighted(fieldName, weighted)
+
+	return f
+}
+
+// EnsureMapKeyOneOf picks the keys of a map field from values. See EnsureOneOf(...)
+//
+// The generated keys are unique, so there must be at least as many values as map items.
+func (f *Factory) EnsureMapKeyOneOf(fieldName string, values ...interface{}) *Factory {
+	f.plan.EnsureMapKeyOneOf(fieldName, values)
+
+	return f
+}
+
+// EnsureMapKeyWeighted picks the keys of a map field in proportion to their weights. See EnsureWeighted(...)
+//
+// The generated keys are unique, so there must be at least as many values with a weight greater than 0 as map items.
+func (f *Factory) EnsureMapKeyWeighted(fieldName string, weighted map[interface{}]int) *Factory {
+	f.plan.EnsureMapKeyWeighted(fieldName, weighted)
+
+	return f
+}
+
+// EnsureDistribution samples the values of a numeric field from the distribution.
+// E.g. EnsureDistribution("Amount", amounts) where amounts, err := LogNormal(3, 1)
+//
+// The distribution applies to the numbers the field holds, so pointers and the elements of slices, arrays and maps are sampled from it.
+// The samples are rounded for integer fields and clamped to the values the field can hold. See Distribution.
+// Use EnsureConstraint(...) with a range to truncate the distribution. E.g. ConstrainFloatRange(0, 1000)
+func (f *Factory) EnsureDistribution(fieldName string, dist Distribution) *Factory {
+	f.plan.EnsureDistribution(fieldName, dist)
+
+	return f
+}
+
+// EnsurePattern generates the strings of the field from a regular expression. E.g. EnsurePattern("SKU", regexp.MustCompile(`[A-Z]{3}-[0-9]{4}`))
+//
+// The pattern applies to the strings the field holds, so pointers and the elements of slices, arrays and maps are generated from it.
+// Anchors and word boundaries are ignored and the unbounded repeats *, + and {n,} are limited to 10 extra repetitions.
+// EnsurePattern takes precedence over the field's tags.
+// A pattern that can't generate strings, e.g. x[^\x00-\x{10FFFF}], is reported as a *FieldPathError by Validate() and ExecuteE().
+func (f *Factory) EnsurePattern(fieldName string, pattern *regexp.Regexp) *Factory {
+	f.plan.EnsurePattern(fieldName, pattern)
+
+	return f
+}
+
+// EnsureMapKeyPattern generates the keys of a map field from a regular expression. See EnsurePattern(...)
+//
+// The generated keys are unique, so the pattern must be able to produce enough distinct keys for the map.
+func (f *Factory) EnsureMapKeyPattern(fieldName string, pattern *regexp.Regexp) *Factory {
+	f.plan.EnsureMapKeyPattern(fieldName, pattern)
+
+	return f
+}
+
+// EnsureMapValuePattern generates the values of a map field from a regular expression. See EnsurePattern(...)
+func (f *Factory) EnsureMapValuePattern(fieldName string, pattern *regexp.Regexp) *Factory {
+	f.plan.EnsureMapValuePattern(fieldName, pattern)
+
+	return f
+}
+
 // EnsureMapKeySequence sets one or more keys for a map field
 func (f *Factory) EnsureMapKeySequence(fieldName string, seq ...interface{}) *Factory {
 	f.plan.EnsureMapKeySequence(fieldName, seq)
@@ -144,7 +383,7 @@ func (f *Factory) EnsureMapValueSequence(fieldName string, seq ...interface{}) *
 // In other words, WithMinItems(n, span, ignored, ignored, ...)
 func (f *Factory) WithMinItems(n int, span ...int) *Factory {
 	f.plan.SetItemCountHandler(func() {
-		f.plan.SetRunCount(MinRun, minItem(n, span))
+		f.plan.SetRunCount(MinRun, minItem(f.plan.rng, n, span))
 	})
 
 	return f
@@ -153,7 +392,7 @@ func (f *Factory) WithMinItems(n int, span ...int) *Factory {
 // WithMaxItems generates up to [0, n] items
 func (f *Factory) WithMaxItems(n int) *Factory {
 	f.plan.SetItemCountHandler(func() {
-		f.plan.SetRunCount(MaxRun, rand.Intn(1+n))
+		f.plan.SetRunCount(MaxRun, f.plan.rng.Intn(1+n))
 	})
 
 	return f
@@ -168,9 +407,20 @@ func (f *Factory) WithExactItems(n int) *Factory {
 	return f
 }
 
+// WithUnboundedItems generates items until the consumer stops reading them.
+// Use it with Stream(...), All(), WriteJSON(...) or WriteJSONLines(...).
+// The methods that collect the items, such as ExecuteE(), WriteCSV(...) and WriteGoLiteral(...), return an *UnboundedError.
+func (f *Factory) WithUnboundedItems() *Factory {
+	f.plan.SetItemCountHandler(func() {
+		f.plan.SetRunCount(UnboundedRun, 0)
+	})
+
+	return f
+}
+
 // WithExactMapItems generates exactly n items for a field that is a map
 func (f *Factory) WithExactMapItems(fieldName string, n int) *Factory {
-	f.plan.SetMapItemCountHandler(fieldName, func() {
+	f.plan.setMapItemCountHandler("WithExactMapItems", fieldName, func() {
 		f.plan.SetMapRunCount(fieldName, ExactRun, n)
 	})
 	return f
@@ -178,8 +428,8 @@ func (f *Factory) WithExactMapItems(fieldName string, n int) *Factory {
 
 // WithMaxMapItems generates up to [0, n] items for a field that is a map
 func (f *Factory) WithMaxMapItems(fieldName string, n int) *Factory {
-	f.plan.SetMapItemCountHandler(fieldName, func() {
-		f.plan.SetMapRunCount(fieldName, MaxRun, rand.Intn(1+n))
+	f.plan.setMapItemCountHandler("WithMaxMapItems", fieldName, func() {
+		f.plan.SetMapRunCount(fieldName, MaxRun, f.plan.rng.Intn(1+n))
 	})
 	return f
 }
@@ -189,12 +439,81 @@ func (f *Factory) WithMaxMapItems(fieldName string, n int) *Factory {
 //
 // See @WithMinItems for more discussion
 func (f *Factory) WithMinMapItems(fieldName string, n int, span ...int) *Factory {
-	f.plan.SetMapItemCountHandler(fieldName, func() {
-		f.plan.SetMapRunCount(fieldName, MinRun, minItem(n, span))
+	f.plan.setMapItemCountHandler("WithMinMapItems", fieldName, func() {
+		f.plan.SetMapRunCount(fieldName, MinRun, minItem(f.plan.rng, n, span))
 	})
 	return f
 }
 
+// RegisterEnum limits every value of type t generated by the factory to one of the values.
+// E.g. RegisterEnum(reflect.TypeOf(Status("")), "active", "suspended", "closed")
+//
+// The values are converted to t. When a value isn't a t, Validate() reports the fields of type t and ExecuteE returns
+// an *UnsupportedKindError. See the package level RegisterEnum(...) to set an enum for all factories.
+func (f *Factory) RegisterEnum(t reflect.Type, values ...interface{}) *Factory {
+	f.plan.RegisterEnum(t, values)
+
+	return f
+}
+
+// RegisterDistribution samples every value of the numeric type t generated by the factory from the distribution.
+// E.g. RegisterDistribution(reflect.TypeOf(time.Duration(0)), latencies) where latencies, err := LogNormal(math.Log(float64(50*time.Millisecond)), 0.5)
+//
+// See EnsureDistribution(...) to sample a single field.
+func (f *Factory) RegisterDistribution(t reflect.Type, dist Distribution) *Factory {
+	f.plan.RegisterDistribution(t, dist)
+
+	return f
+}
+
+// RegisterTypeGenerator sets the generator used for every value of type t.
+//
+// The generator is used at every nesting level, including slice elements, map keys and values, and pointers.
+// Ensure(...), EnsureSequence(...) and OnField(...) still take precedence for the fields they are set on.
+// See the package level RegisterTypeGenerator(...) to set a generator for all factories.
+func (f *Factory) RegisterTypeGenerator(t reflect.Type, generator GenType) *Factory {
+	f.plan.RegisterTypeGenerator(t, generator)
+
+	return f
+}
+
+// WithTimeRange generates the time.Time values of the field within [from, to).
+//
+// By default time.Time values are generated between 2000-01-01 and 2030-01-01 UTC.
+func (f *Factory) WithTimeRange(fieldName string, from time.Time, to time.Time) *Factory {
+	f.plan.SetTimeRange(fieldName, from, to)
+
+	return f
+}
+
+// WithTimeLocation sets the location of the generated time.Time values. Defaults to time.UTC
+func (f *Factory) WithTimeLocation(loc *time.Location) *Factory {
+	f.plan.SetTimeLocation(loc)
+
+	return f
+}
+
+// WithMonotonicTime generates increasing time.Time values for the field as the items are generated.
+//
+// The first item gets the start of the field's time range (see WithTimeRange) and each of the
+// following items is (0, maxStep] after the previous item.
+func (f *Factory) WithMonotonicTime(fieldName string, maxStep time.Duration) *Factory {
+	f.plan.SetMonotonicTime(fieldName, maxStep)
+
+	return f
+}
+
+// WithValidatorTags generates values that pass the go-playground/validator rules of the fields.
+// E.g. `validate:"required,min=3,max=40"`, `validate:"email"` or `validate:"oneof=red green"`.
+//
+// The rules are converted to salem tag directives, so fields with a salem tag ignore their validate tag.
+// The rules that can't be converted are skipped while generating and reported by Validate().
+func (f *Factory) WithValidatorTags() *Factory {
+	f.plan.SetValidatorTags(true)
+
+	return f
+}
+
 func (f *Factory) OnField(fieldName string, handler fieldHandlerType) *Factory {
 	f.plan.RemoveFieldHandler(fieldName) // Automatically removes existing field handlers
 	f.plan.AddFieldHandler(fieldName, handler)
@@ -203,12 +522,12 @@ func (f *Factory) OnField(fieldName string, handler fieldHandlerType) *Factory {
 }
 
 // minItem returns a an in generated [n, n+10) items or [n, n+span[0])
-func minItem(n int, span []int) int {
+func minItem(rng *rand.Rand, n int, span []int) int {
 	var upperBounds int = 10
 
 	if len(span) > 0 && span[0] > 0 {
 		upperBounds = span[0]
 	}
 
-	return n + rand.Intn(upperBounds)
+	return n + rng.Intn(upperBounds)
 }
@@ -0,0 +1,123 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type point struct {
+	X float64
+	Y float64
+}
+
+type shape struct {
+	GUID     [16]byte
+	Vector   [3]float64
+	Corners  [4]point
+	Anchors  [2]*point
+	Matrix   [2][2]int
+	Hash     *[4]uint32
+	Vertices [][3]float64
+	Layers   [2]map[string]int
+}
+
+func Test_FactoryArray(t *testing.T) {
+	test_array_fields(t)
+	test_array_ensure(t)
+	test_array_sequence(t)
+	test_array_element_ensure(t)
+	test_array_nested_element_fields(t)
+	test_array_element_maps(t)
+	test_array_validate(t)
+}
+
+func test_array_fields(t *testing.T) {
+	result, err := salem.For[shape]().ExecuteE()
+
+	assert.Nil(t, err, "expect array fields to be mocked")
+	assert.NotEqual(t, [16]byte{}, result[0].GUID, "expect byte array to be mocked")
+	assert.NotEqual(t, [3]float64{}, result[0].Vector, "expect float array to be mocked")
+	assert.NotEmpty(t, result[0].Corners[3].X, "expect struct elements to be mocked")
+	assert.NotNil(t, result[0].Anchors[1], "expect pointer elements to be mocked")
+	assert.NotNil(t, result[0].Hash, "expect pointer to array to be mocked")
+	assert.Equal(t, 1, len(result[0].Vertices), "expect slice of arrays to be mocked")
+	assert.NotEqual(t, [3]float64{}, result[0].Vertices[0], "expect slice of arrays to be mocked")
+}
+
+func test_array_ensure(t *testing.T) {
+	vector := [3]float64{1, 2, 3}
+
+	result := salem.For[shape]().
+		Ensure("Vector", vector).
+		One()
+
+	assert.Equal(t, vector, result.Vector, "expect Ensure(...) to set the whole array")
+}
+
+func test_array_sequence(t *testing.T) {
+	results := salem.For[shape]().
+		EnsureSequence("Vector", [3]float64{1, 1, 1}, [3]float64{2, 2, 2}).
+		WithExactItems(2).
+		Execute()
+
+	assert.Equal(t, [3]float64{1, 1, 1}, results[0].Vector, "expect EnsureSequence(...) to set the whole array")
+	assert.Equal(t, [3]float64{2, 2, 2}, results[1].Vector)
+}
+
+func test_array_element_ensure(t *testing.T) {
+	results := salem.For[shape]().
+		Ensure("GUID[0]", byte(0xff)).
+		EnsureSequence("Vector[2]", 10.0, 20.0).
+		Ensure("Matrix[1][1]", 7).
+		Omit("Corners[0]").
+		WithExactItems(2).
+		Execute()
+
+	assert.Equal(t, byte(0xff), results[0].GUID[0], "expect Ensure(...) to set an element")
+	assert.Equal(t, 10.0, results[0].Vector[2], "expect EnsureSequence(...) to set an element")
+	assert.Equal(t, 20.0, results[1].Vector[2])
+	assert.Equal(t, 7, results[0].Matrix[1][1], "expect nested array elements to be set")
+	assert.Equal(t, point{}, results[0].Corners[0], "expect Omit(...) to skip an element")
+}
+
+func test_array_nested_element_fields(t *testing.T) {
+	result := salem.For[shape]().
+		Ensure("Corners.X", 1.5).
+		Ensure("Corners[2].Y", 4.5).
+		One()
+
+	assert.Equal(t, 1.5, result.Corners[0].X, "expect Ensure(...) to set the fields of all elements")
+	assert.Equal(t, 1.5, result.Corners[3].X)
+	assert.Equal(t, 4.5, result.Corners[2].Y, "expect Ensure(...) to set the field of one element")
+}
+
+func test_array_element_maps(t *testing.T) {
+	result := salem.For[shape]().
+		WithExactMapItems("Layers[0]", 3).
+		EnsureMapKeySequence("Layers[1]", "top").
+		WithExactMapItems("Layers[1]", 1).
+		One()
+
+	assert.Equal(t, 3, len(result.Layers[0]), "expect the map options to apply to the array element")
+	assert.Contains(t, result.Layers[1], "top", "expect the map key options to apply to the array element")
+}
+
+func test_array_validate(t *testing.T) {
+	valid := salem.For[shape]().
+		Ensure("GUID[15]", byte(1)).
+		Ensure("Matrix[1][0]", 1).
+		Ensure("Corners[2].Y", 4.5).
+		Ensure("Corners.X", 1.5)
+	assert.Nil(t, valid.Validate(), "expect element paths to be valid")
+
+	invalid := salem.For[shape]().
+		Ensure("GUID[16]", byte(1)).
+		Ensure("Vertices[0]", [3]float64{})
+	err := invalid.Validate().(*salem.ValidationError)
+	assert.Equal(t, 2, len(err.Errors), "expect out of range and slice element paths to be reported")
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tier string
+
+type region string
+
+type account struct {
+	Status   string
+	Tier     tier
+	Region   region
+	Tags     []string
+	Previous *string
+	Limits   map[string]int
+	Regions  map[region]int
+	Upgrades []tier
+	Slots    [2]int
+}
+
+func Test_FactoryChoice(t *testing.T) {
+	test_choice_one_of(t)
+	test_choice_weighted(t)
+	test_choice_map_keys_and_values(t)
+	test_choice_enums(t)
+	test_choice_errors(t)
+	test_choice_array_elements(t)
+}
+
+func test_choice_one_of(t *testing.T) {
+	results := salem.For[account]().
+		EnsureOneOf("Status", "active", "suspended", "closed").
+		EnsureOneOf("Tier", "free", "pro").
+		EnsureOneOf("Tags", "red", "green").
+		EnsureOneOf("Previous", "basic").
+		WithExactItems(20).
+		Execute()
+
+	for _, a := range results {
+		assert.Contains(t, []string{"active", "suspended", "closed"}, a.Status, "expect one of the values")
+		assert.Contains(t, []tier{"free", "pro"}, a.Tier, "expect the values to be converted to the named type")
+		assert.Equal(t, "basic", *a.Previous, "expect the choice to apply to the pointer's value")
+
+		for _, tag := range a.Tags {
+			assert.Contains(t, []string{"red", "green"}, tag, "expect the choice to apply to the slice elements")
+		}
+	}
+}
+
+func test_choice_weighted(t *testing.T) {
+	weights := map[interface{}]int{"active": 80, "suspended": 15, "closed": 5, "deleted": 0}
+
+	results := salem.For[account]().EnsureWeighted("Status", weights).WithExactItems(2000).WithSeed(7).Execute()
+
+	counts := map[string]int{}
+	for _, a := range results {
+		counts[a.Status]++
+	}
+
+	assert.True(t, counts["active"] > 1400 && counts["active"] < 1800, "expect the values in proportion to their weights")
+	assert.True(t, counts["closed"] > 0 && counts["closed"] < counts["suspended"], "expect the values in proportion to their weights")
+	assert.Zero(t, counts["deleted"], "expect values with a weight of 0 to be skipped")
+
+	replay := salem.For[account]().EnsureWeighted("Status", weights).WithExactItems(2000).WithSeed(7).Execute()
+	assert.Equal(t, results, replay, "expect the same seed to pick the same values")
+}
+
+func test_choice_map_keys_and_values(t *testing.T) {
+	results := salem.For[account]().
+		EnsureMapKeyOneOf("Limits", "cpu", "memory", "disk").
+		EnsureOneOf("Limits", 1, 2, 4).
+		WithExactMapItems("Limits", 3).
+		WithExactItems(5).
+		Execute()
+
+	for _, a := range results {
+		assert.Equal(t, 3, len(a.Limits))
+		for key, value := range a.Limits {
+			assert.Contains(t, []string{"cpu", "memory", "disk"}, key, "expect EnsureMapKeyOneOf(...) to pick the keys")
+			assert.Contains(t, []int{1, 2, 4}, value, "expect EnsureOneOf(...) to pick the map values")
+		}
+	}
+
+	_, err := salem.For[account]().
+		EnsureMapKeyOneOf("Limits", "cpu").
+		WithExactMapItems("Limits", 2).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the keys run out")
+
+	weighted := salem.For[account]().
+		EnsureMapKeyWeighted("Limits", map[interface{}]int{"cpu": 1, "memory": 1, "disk": 0}).
+		WithExactMapItems("Limits", 2).
+		WithExactItems(5).
+		Execute()
+
+	for _, a := range weighted {
+		_, hasCPU := a.Limits["cpu"]
+		_, hasMemory := a.Limits["memory"]
+		assert.True(t, hasCPU && hasMemory, "expect EnsureMapKeyWeighted(...) to skip the keys with a weight of 0")
+	}
+}
+
+func test_choice_enums(t *testing.T) {
+	salem.RegisterEnum(reflect.TypeOf(region("")), "emea", "apac")
+	defer salem.UnregisterEnum(reflect.TypeOf(region("")))
+
+	results := salem.For[account]().
+		RegisterEnum(reflect.TypeOf(tier("")), "free", "pro", "team").
+		WithExactMapItems("Regions", 2).
+		WithExactItems(10).
+		Execute()
+
+	for _, a := range results {
+		assert.Contains(t, []tier{"free", "pro", "team"}, a.Tier, "expect the factory's enum")
+		assert.Contains(t, []region{"emea", "apac"}, a.Region, "expect the package's enum")
+		assert.Equal(t, 2, len(a.Regions))
+
+		for key := range a.Regions {
+			assert.Contains(t, []region{"emea", "apac"}, key, "expect the enum to apply to the map keys")
+		}
+		for _, upgrade := range a.Upgrades {
+			assert.Contains(t, []tier{"free", "pro", "team"}, upgrade, "expect the enum to apply to the slice elements")
+		}
+	}
+
+	a := salem.For[account]().
+		RegisterEnum(reflect.TypeOf(tier("")), "free").
+		EnsureOneOf("Tier", "legacy").
+		One()
+	assert.Equal(t, tier("legacy"), a.Tier, "expect EnsureOneOf(...) to take precedence over the enum")
+}
+
+func test_choice_errors(t *testing.T) {
+	var validationErr *salem.ValidationError
+	var pathErr *salem.FieldPathError
+	var kindErr *salem.UnsupportedKindError
+
+	invalid := salem.For[account]().
+		EnsureOneOf("Status").
+		EnsureWeighted("Previous", map[interface{}]int{"active": -1}).
+		EnsureMapKeyWeighted("Limits", map[interface{}]int{"cpu": 0})
+	assert.True(t, errors.As(invalid.Validate(), &validationErr), "expect Validate() to report the values that can't be chosen")
+	assert.Equal(t, 3, len(validationErr.Errors), "expect no values, negative weights and zero weights to be reported")
+
+	_, err := invalid.ExecuteE()
+	assert.True(t, errors.As(err, &pathErr), "expect ExecuteE() to report the values that can't be chosen")
+	assert.Contains(t, err.Error(), "at least one value is needed")
+
+	salem.RegisterEnum(reflect.TypeOf(tier("")), 1)
+	defer salem.UnregisterEnum(reflect.TypeOf(tier("")))
+
+	assert.True(t, errors.As(salem.For[account]().Validate(), &validationErr), "expect Validate() to report the enum")
+	assert.Equal(t, 2, len(validationErr.Errors), "expect the fields of the enum's type to be reported")
+	assert.Equal(t, "Tier", validationErr.Errors[0].FieldName)
+
+	_, err = salem.For[account]().ExecuteE()
+	assert.True(t, errors.As(err, &kindErr), "expect values of another kind to fail the generation")
+	assert.Equal(t, "Tier", kindErr.FieldName)
+
+	_, err = salem.For[account]().RegisterEnum(reflect.TypeOf(tier(""))).ExecuteE()
+	assert.True(t, errors.As(err, &kindErr), "expect the factory's enum to need at least one value")
+	salem.UnregisterEnum(reflect.TypeOf(tier("")))
+
+	assert.Nil(t, salem.For[account]().EnsureOneOf("Upgrades", "free").EnsureMapKeyOneOf("Regions", "emea").Validate(),
+		"expect the values to fit the elements and keys")
+
+	err = salem.For[account]().
+		EnsureOneOf("Status", 1, 2).
+		EnsureMapKeyOneOf("Limits", 1).
+		EnsureOneOf("Stauts", "active").
+		Validate()
+
+	assert.True(t, errors.As(err, &validationErr), "expect a *ValidationError")
+	assert.Equal(t, 3, len(validationErr.Errors), "expect values that don't fit and unknown paths to be reported")
+
+	_, err = salem.For[account]().EnsureOneOf("Status", 1, 2).ExecuteE()
+	assert.True(t, errors.As(err, &pathErr), "expect values that don't fit to fail the generation")
+	assert.Equal(t, "Status", pathErr.FieldName)
+
+	_, err = salem.For[account]().EnsureOneOf("Tags", 1).ExecuteE()
+	assert.True(t, errors.As(err, &pathErr), "expect values that don't fit the elements to fail the generation")
+
+	_, err = salem.For[account]().EnsureMapKeyOneOf("Limits", 1).ExecuteE()
+	assert.True(t, errors.As(err, &pathErr), "expect keys that don't fit to fail the generation")
+	assert.Equal(t, "Limits", pathErr.FieldName)
+}
+
+func test_choice_array_elements(t *testing.T) {
+	results := salem.For[account]().
+		EnsureOneOf("Slots[1]", 7).
+		EnsureWeighted("Slots[0]", map[interface{}]int{1: 1, 2: 1}).
+		WithExactItems(10).
+		Execute()
+
+	for _, a := range results {
+		assert.Equal(t, 7, a.Slots[1], "expect EnsureOneOf(...) to apply to the array element")
+		assert.Contains(t, []int{1, 2}, a.Slots[0], "expect EnsureWeighted(...) to apply to the array element")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type invoice struct {
+	Number    int
+	Reference string
+	Discount  float64
+}
+
+var isEven = salem.Predicate(func(v interface{}) bool { return v.(int)%2 == 0 }, "even")
+
+func Test_FactoryConstraintCombinators(t *testing.T) {
+	test_and_constraint(t)
+	test_or_constraint(t)
+	test_not_constraint(t)
+	test_stacked_constraints(t)
+	test_combinator_errors(t)
+}
+
+func test_and_constraint(t *testing.T) {
+	results := salem.For[invoice]().
+		EnsureConstraint("Number", salem.And(salem.ConstrainIntRange(10000, 20000), isEven)).
+		WithExactItems(20).
+		Execute()
+
+	for _, r := range results {
+		assert.True(t, r.Number >= 10000 && r.Number <= 20000, "expect the range to generate the values")
+		assert.Equal(t, 0, r.Number%2, "expect all of the constraints to be met")
+	}
+}
+
+func test_or_constraint(t *testing.T) {
+	results := salem.For[invoice]().
+		EnsureConstraint("Number", salem.Or(salem.ConstrainIntRange(0, 10), salem.ConstrainIntRange(9000, 9010))).
+		WithExactItems(50).
+		Execute()
+
+	var low, high int
+	for _, r := range results {
+		switch {
+		case r.Number >= 0 && r.Number <= 10:
+			low++
+		case r.Number >= 9000 && r.Number <= 9010:
+			high++
+		}
+	}
+
+	assert.Equal(t, 50, low+high, "expect one of the constraints to be met")
+	assert.True(t, low > 0 && high > 0, "expect values from each of the constraints")
+}
+
+func test_not_constraint(t *testing.T) {
+	startsWithA := salem.Predicate(func(v interface{}) bool { return strings.HasPrefix(v.(string), "A") }, "starts with A")
+
+	results := salem.For[invoice]().
+		EnsureConstraint("Reference", salem.Not(startsWithA)).
+		WithExactItems(20).
+		Execute()
+
+	for _, r := range results {
+		assert.False(t, strings.HasPrefix(r.Reference, "A"))
+	}
+}
+
+func test_stacked_constraints(t *testing.T) {
+	results := salem.For[invoice]().
+		EnsureConstraint("Number", salem.ConstrainIntRange(100, 200)).
+		EnsureConstraint("Number", isEven).
+		EnsureConstraint("Discount", salem.ConstrainFloatRange(0, 0.5)).
+		EnsureConstraint("Discount", salem.ConstrainFloatRange(0.25, 1)).
+		WithExactItems(20).
+		Execute()
+
+	for _, r := range results {
+		assert.True(t, r.Number >= 100 && r.Number <= 200, "expect the first constraint to be kept")
+		assert.Equal(t, 0, r.Number%2, "expect the second constraint to be added")
+		assert.True(t, r.Discount >= 0.25 && r.Discount <= 0.5)
+	}
+}
+
+func test_combinator_errors(t *testing.T) {
+	_, err := salem.For[invoice]().
+		Ensure("Number", 15).
+		EnsureConstraint("Number", salem.ConstrainIntRange(10, 20)).
+		EnsureConstraint("Number", isEven).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError")
+	assert.Equal(t, isEven, constraintErr.Failed, "expect the sub-constraint that failed")
+	assert.Contains(t, err.Error(), "Failed: even")
+
+	isOdd := salem.Predicate(func(v interface{}) bool { return v.(int)%2 == 1 }, "odd")
+	_, err = salem.For[invoice]().
+		EnsureConstraint("Number", salem.And(salem.ConstrainIntRange(0, 100), isEven, isOdd)).
+		ExecuteE()
+
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the constraints can't be met")
+	assert.Contains(t, err.Error(), "And(IntRange[0, 100], even, odd)")
+	assert.True(t, constraintErr.Failed == isEven || constraintErr.Failed == isOdd, "expect the sub-constraint that failed")
+}
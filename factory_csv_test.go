@@ -0,0 +1,161 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"go-salem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type subscriber struct {
+	ID       int
+	Name     string
+	Active   bool
+	Joined   time.Time
+	Address  address
+	Backup   *address
+	Tags     []string
+	Scores   map[string]int
+	Secret   string `salem:"omit"`
+	internal int
+}
+
+type address struct {
+	Street string
+	Zip    string
+}
+
+func Test_FactoryCSV(t *testing.T) {
+	test_csv_columns(t)
+	test_csv_json_cells(t)
+	test_csv_repeated_columns(t)
+	test_csv_skip(t)
+	test_csv_headers_and_order(t)
+	test_csv_json_errors(t)
+}
+
+// readCSV writes the factory's mocks as CSV and parses them back
+func readCSV(t *testing.T, f *salem.TypedFactory[subscriber], opts salem.CSVOptions) [][]string {
+	var buf bytes.Buffer
+	assert.Nil(t, f.WriteCSV(&buf, opts), "expect the CSV to be written")
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.Nil(t, err, "expect valid CSV")
+
+	return records
+}
+
+func test_csv_columns(t *testing.T) {
+	records := readCSV(t, salem.For[subscriber]().
+		Ensure("Name", "Ada, \"the first\"").
+		Ensure("Address.Zip", "12345").
+		Omit("Backup.Zip").
+		WithExactItems(3), salem.CSVOptions{})
+
+	assert.Equal(t, 4, len(records), "expect a header and a row per item")
+	assert.Equal(t, []string{"ID", "Name", "Active", "Joined", "Address.Street", "Address.Zip", "Backup.Street", "Tags", "Scores"}, records[0],
+		"expect dotted columns for nested structs without the omitted and private fields")
+
+	for _, row := range records[1:] {
+		assert.Equal(t, "Ada, \"the first\"", row[1], "expect the cells to be quoted")
+		assert.Equal(t, "12345", row[5])
+
+		_, err := time.Parse(time.RFC3339Nano, row[3])
+		assert.Nil(t, err, "expect RFC 3339 times")
+	}
+}
+
+func test_csv_json_cells(t *testing.T) {
+	records := readCSV(t, salem.For[subscriber]().
+		EnsureSequence("Tags", []string{"a", "b"}).
+		EnsureMapKeySequence("Scores", "math").
+		EnsureMapValueSequence("Scores", 90).
+		WithExactMapItems("Scores", 1).
+		Ensure("Backup", address{Street: "Main", Zip: "1"}).
+		WithExactItems(1), salem.CSVOptions{Pointers: salem.CSVJSON})
+
+	assert.Equal(t, []string{"ID", "Name", "Active", "Joined", "Address.Street", "Address.Zip", "Backup", "Tags", "Scores"}, records[0])
+	assert.Equal(t, `{"Street":"Main","Zip":"1"}`, records[1][6], "expect a JSON cell for the pointer")
+	assert.Equal(t, `["a","b"]`, records[1][7], "expect a JSON cell for the slice by default")
+	assert.Equal(t, `{"math":90}`, records[1][8], "expect a JSON cell for the map by default")
+}
+
+func test_csv_repeated_columns(t *testing.T) {
+	records := readCSV(t, salem.For[subscriber]().
+		EnsureSequence("Tags", []string{"a"}, []string{"b", "c"}).
+		EnsureDerived("Backup", func(s subscriber) interface{} {
+			if len(s.Tags) == 1 {
+				return nil
+			}
+			return &address{Street: "Main"}
+		}).
+		EnsureMapKeySequence("Scores", "math", "art").
+		WithExactMapItems("Scores", 2).
+		WithExactItems(2), salem.CSVOptions{Slices: salem.CSVColumns, Maps: salem.CSVColumns})
+
+	header := strings.Join(records[0], ",")
+	assert.Contains(t, header, "Tags[0],Tags[1],Scores[art],Scores[math]", "expect a column per element and sorted map keys")
+
+	assert.Equal(t, []string{"a", ""}, records[1][8:10], "expect empty cells for the missing elements")
+	assert.Equal(t, []string{"b", "c"}, records[2][8:10])
+	assert.Equal(t, "", records[1][6], "expect an empty cell for the nil pointer")
+	assert.Equal(t, "Main", records[2][6], "expect the pointer's value")
+}
+
+func test_csv_skip(t *testing.T) {
+	records := readCSV(t, salem.For[subscriber](), salem.CSVOptions{Slices: salem.CSVSkip, Maps: salem.CSVSkip, Pointers: salem.CSVSkip})
+
+	assert.Equal(t, []string{"ID", "Name", "Active", "Joined", "Address.Street", "Address.Zip"}, records[0], "expect the skipped columns to be left out")
+}
+
+func test_csv_headers_and_order(t *testing.T) {
+	opts := salem.CSVOptions{
+		Columns: []string{"Name", "Address", "ID"},
+		Headers: map[string]string{"Name": "name", "Address.Zip": "postcode"},
+		Comma:   ';',
+	}
+
+	var buf bytes.Buffer
+	err := salem.For[subscriber]().Ensure("ID", 7).WithExactItems(2).WriteCSV(&buf, opts)
+	assert.Nil(t, err)
+
+	reader := csv.NewReader(&buf)
+	reader.Comma = ';'
+	records, err := reader.ReadAll()
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"name", "Address.Street", "postcode", "ID"}, records[0], "expect the selected columns in order with the new headers")
+	assert.Equal(t, "7", records[1][3])
+
+	err = salem.For[subscriber]().WriteCSV(&buf, salem.CSVOptions{Columns: []string{"Adress"}})
+
+	var pathErr *salem.FieldPathError
+	assert.True(t, errors.As(err, &pathErr), "expect a *FieldPathError for unknown columns")
+	assert.Equal(t, "Address", pathErr.Suggestion)
+}
+
+func test_csv_json_errors(t *testing.T) {
+	type flags struct {
+		Name    string
+		Enabled map[bool]int
+	}
+
+	var buf bytes.Buffer
+	err := salem.For[flags]().WriteCSV(&buf, salem.CSVOptions{})
+
+	assert.NotNil(t, err, "expect the JSON cell's error")
+	assert.Contains(t, err.Error(), "'Enabled'", "expect the column to be reported")
+	assert.Contains(t, err.Error(), "json: ", "expect the encoding/json error")
+	assert.Empty(t, buf.String(), "expect nothing to be written")
+
+	err = salem.For[flags]().WriteCSV(&buf, salem.CSVOptions{Maps: salem.CSVSkip})
+	assert.Nil(t, err, "expect skipped maps not to be marshalled")
+}
@@ -0,0 +1,190 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sale struct {
+	Amount   float64
+	Quantity int64
+	Rank     uint16
+	Latency  time.Duration
+	Discount *float32
+	Scores   []int
+	Retries  int32
+	Note     string
+	Tiers    [2]int
+}
+
+func Test_FactoryDistribution(t *testing.T) {
+	test_distribution_int64(t)
+	test_distribution_fields(t)
+	test_distribution_skew(t)
+	test_distribution_types(t)
+	test_distribution_clamping(t)
+	test_distribution_validate(t)
+	test_distribution_array_elements(t)
+}
+
+func test_distribution_int64(t *testing.T) {
+	results := salem.For[sale]().WithExactItems(20).Execute()
+
+	for _, o := range results {
+		assert.True(t, o.Quantity >= 0, "expect int64 fields to be generated")
+	}
+}
+
+func test_distribution_fields(t *testing.T) {
+	results := salem.For[sale]().
+		EnsureDistribution("Amount", dist(salem.Normal(100, 10))).
+		EnsureDistribution("Quantity", dist(salem.Poisson(4))).
+		EnsureDistribution("Discount", dist(salem.Uniform(0.1, 0.3))).
+		EnsureDistribution("Scores", dist(salem.Uniform(50, 60))).
+		WithExactItems(2000).
+		WithSeed(11).
+		Execute()
+
+	var amounts, quantities float64
+	for _, o := range results {
+		amounts += o.Amount
+		quantities += float64(o.Quantity)
+
+		assert.True(t, o.Quantity >= 0, "expect Poisson(...) to be positive")
+		assert.True(t, *o.Discount >= 0.1 && *o.Discount < 0.3, "expect the distribution to apply to the pointer's value")
+		for _, score := range o.Scores {
+			assert.True(t, score >= 50 && score <= 60, "expect the distribution to apply to the slice elements")
+		}
+	}
+
+	assert.InDelta(t, 100, amounts/2000, 1, "expect the mean of Normal(...)")
+	assert.InDelta(t, 4, quantities/2000, 0.2, "expect the mean of Poisson(...)")
+}
+
+func test_distribution_skew(t *testing.T) {
+	median := float64(50 * time.Millisecond)
+
+	zipf, err := salem.Zipf(1.5, 1, 100)
+	assert.Nil(t, err)
+
+	results := salem.For[sale]().
+		EnsureDistribution("Latency", dist(salem.LogNormal(math.Log(median), 0.5))).
+		EnsureDistribution("Rank", zipf).
+		EnsureDistribution("Amount", dist(salem.Exponential(0.5))).
+		WithExactItems(2000).
+		WithSeed(3).
+		Execute()
+
+	var belowMedian, topRank int
+	var amounts float64
+	for _, o := range results {
+		if o.Latency < time.Duration(median) {
+			belowMedian++
+		}
+		if o.Rank == 0 {
+			topRank++
+		}
+		amounts += o.Amount
+
+		assert.True(t, o.Latency > 0, "expect LogNormal(...) to be positive")
+		assert.True(t, o.Rank <= 100, "expect Zipf(...) to be limited to imax")
+		assert.True(t, o.Amount >= 0, "expect Exponential(...) to be positive")
+	}
+
+	assert.InDelta(t, 1000, belowMedian, 100, "expect half the latencies below the median")
+	assert.True(t, topRank > 2000/5, "expect the first rank to be the most common")
+	assert.InDelta(t, 2, amounts/2000, 0.2, "expect the mean of Exponential(...)")
+
+	ranks := salem.For[sale]().EnsureDistribution("Rank", zipf).WithExactItems(50).WithSeed(5)
+	assert.Equal(t, ranks.Execute(), ranks.Execute(), "expect Zipf(...) to follow the random source of each run")
+}
+
+func test_distribution_types(t *testing.T) {
+	results := salem.For[sale]().
+		RegisterDistribution(reflect.TypeOf(int32(0)), dist(salem.Uniform(1, 3))).
+		RegisterDistribution(reflect.TypeOf(time.Duration(0)), dist(salem.Uniform(float64(time.Second), float64(2*time.Second)))).
+		EnsureDistribution("Retries", dist(salem.Uniform(7, 8))).
+		WithExactItems(10).
+		Execute()
+
+	for _, o := range results {
+		assert.True(t, o.Latency >= time.Second && o.Latency <= 2*time.Second, "expect the type's distribution")
+		assert.True(t, o.Retries >= 7 && o.Retries <= 8, "expect EnsureDistribution(...) to take precedence over the type")
+	}
+}
+
+func test_distribution_clamping(t *testing.T) {
+	o := salem.For[sale]().
+		EnsureDistribution("Rank", dist(salem.Normal(-1000, 1))).
+		EnsureDistribution("Retries", dist(salem.Normal(1e12, 1))).
+		EnsureDistribution("Amount", dist(salem.Normal(20, 10))).
+		EnsureConstraint("Amount", salem.ConstrainFloatRange(0, 30)).
+		One()
+
+	assert.Equal(t, uint16(0), o.Rank, "expect negative samples to be 0 for unsigned fields")
+	assert.Equal(t, int32(math.MaxInt32), o.Retries, "expect the samples to be clamped to the kind")
+	assert.True(t, o.Amount >= 0 && o.Amount <= 30, "expect the constraint to truncate the distribution")
+
+	var distErr *salem.DistributionError
+	invalid := map[string]func() (salem.Distribution, error){
+		"Zipf":        func() (salem.Distribution, error) { return salem.Zipf(1, 1, 10) },
+		"Normal":      func() (salem.Distribution, error) { return salem.Normal(0, -1) },
+		"LogNormal":   func() (salem.Distribution, error) { return salem.LogNormal(0, -1) },
+		"Exponential": func() (salem.Distribution, error) { return salem.Exponential(0) },
+		"Poisson":     func() (salem.Distribution, error) { return salem.Poisson(-1) },
+		"Uniform":     func() (salem.Distribution, error) { return salem.Uniform(2, 1) },
+	}
+
+	for name, create := range invalid {
+		d, err := create()
+		assert.Nil(t, d)
+		assert.True(t, errors.As(err, &distErr), "expect invalid %v(...) parameters to return a *DistributionError", name)
+		assert.Equal(t, name, distErr.Distribution)
+	}
+}
+
+func test_distribution_validate(t *testing.T) {
+	err := salem.For[sale]().
+		EnsureDistribution("Scores", dist(salem.Normal(0, 1))).
+		EnsureDistribution("Discount", dist(salem.Normal(0, 1))).
+		Validate()
+	assert.Nil(t, err, "expect numeric fields to pass validation")
+
+	err = salem.For[sale]().
+		EnsureDistribution("Note", dist(salem.Normal(0, 1))).
+		EnsureDistribution("Amout", dist(salem.Normal(0, 1))).
+		Validate()
+
+	var validationErr *salem.ValidationError
+	assert.True(t, errors.As(err, &validationErr), "expect a *ValidationError")
+	assert.Equal(t, 2, len(validationErr.Errors), "expect non-numeric fields and unknown paths to be reported")
+}
+
+func test_distribution_array_elements(t *testing.T) {
+	results := salem.For[sale]().
+		EnsureDistribution("Tiers[0]", dist(salem.Uniform(10, 12))).
+		WithExactItems(10).
+		Execute()
+
+	for _, s := range results {
+		assert.True(t, s.Tiers[0] >= 10 && s.Tiers[0] <= 12, "expect EnsureDistribution(...) to apply to the array element")
+	}
+}
+
+// dist returns the distribution of a constructor that is known to succeed. E.g. dist(salem.Normal(0, 1))
+func dist(d salem.Distribution, err error) salem.Distribution {
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
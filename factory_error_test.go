@@ -0,0 +1,119 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rejectConstraint struct{}
+
+// So that rejectConstraint conforms to salem.FieldConstraint
+func (r *rejectConstraint) IsValid(field interface{}) bool {
+	return false
+}
+
+func Test_FactoryExecuteE(t *testing.T) {
+	test_execute_e_success(t)
+	test_execute_e_constraint_clash(t)
+	test_execute_e_constraint_retries(t)
+	test_execute_e_unsupported_kind(t)
+	test_execute_e_unsupported_map_key(t)
+	test_duplicate_field_handler(t)
+}
+
+func test_execute_e_success(t *testing.T) {
+	results, err := salem.Mock(school{}).WithExactItems(2).ExecuteE()
+
+	assert.Nil(t, err, "expect no error")
+	assert.Equal(t, 2, len(results))
+}
+
+func test_execute_e_constraint_clash(t *testing.T) {
+	type human struct {
+		Name string
+	}
+
+	_, err := salem.Mock(human{}).
+		Ensure("Name", "xxxxx xxxxx xxxxx xxxxx xxxxx yyyy").
+		EnsureConstraint("Name", salem.ConstrainStringLength(4, 20)).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when an Ensure(...) clashes with a constraint")
+	assert.Equal(t, "Name", constraintErr.FieldName)
+	assert.Equal(t, 0, constraintErr.ItemIndex)
+	assert.Equal(t, 0, constraintErr.Attempts, "expect no attempts for ensured values")
+}
+
+func test_execute_e_constraint_retries(t *testing.T) {
+	type human struct {
+		Name string
+	}
+	type family struct {
+		Child human
+	}
+
+	constraint := &rejectConstraint{}
+	f := salem.Mock(family{}).
+		EnsureConstraint("Child.Name", constraint).
+		WithExactItems(2)
+	f.GetPlan().SetMaxConstraintsRetryAttempts(3)
+
+	_, err := f.ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the retries are exhausted")
+	assert.Equal(t, "Child.Name", constraintErr.FieldName, "expect the qualified field name")
+	assert.Equal(t, constraint, constraintErr.Constraint, "expect the failing constraint")
+	assert.Equal(t, 4, constraintErr.Attempts)
+
+	assert.Panics(t, func() {
+		f.Execute()
+	}, "expect Execute to panic")
+}
+
+func test_execute_e_unsupported_kind(t *testing.T) {
+	type pipe struct {
+		Events chan int
+	}
+
+	_, err := salem.Mock(pipe{}).ExecuteE()
+
+	var kindErr *salem.UnsupportedKindError
+	assert.True(t, errors.As(err, &kindErr), "expect an *UnsupportedKindError for unknown kinds")
+	assert.Equal(t, "Events", kindErr.FieldName)
+}
+
+func test_execute_e_unsupported_map_key(t *testing.T) {
+	type key struct {
+		ID string
+	}
+	type lookup struct {
+		Values map[key]string
+	}
+
+	_, err := salem.Mock(lookup{}).ExecuteE()
+
+	var kindErr *salem.UnsupportedKindError
+	assert.True(t, errors.As(err, &kindErr), "expect an *UnsupportedKindError for non-primitive map keys")
+	assert.Equal(t, "Values", kindErr.FieldName)
+}
+
+func test_duplicate_field_handler(t *testing.T) {
+	p := salem.NewPlan()
+	handler := func(int) interface{} { return "" }
+
+	p.AddFieldHandler("Name", handler)
+
+	defer func() {
+		_, ok := recover().(*salem.FieldPathError)
+		assert.True(t, ok, "expect a *FieldPathError for duplicate field handlers")
+	}()
+	p.AddFieldHandler("Name", handler)
+}
@@ -0,0 +1,111 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// evenConstraint generates even numbers and counts the calls to its methods
+type evenConstraint struct {
+	validCalls    int
+	generateCalls int
+	failGenerate  bool
+}
+
+func (c *evenConstraint) IsValid(field interface{}) bool {
+	c.validCalls++
+
+	return reflect.ValueOf(field).Int()%2 == 0
+}
+
+func (c *evenConstraint) Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	c.generateCalls++
+	if c.failGenerate {
+		return nil, false
+	}
+
+	return 2 * rng.Intn(1000), true
+}
+
+type ticket struct {
+	Number int
+	Seat   int8
+	Code   string
+	Gate   accountStatus
+}
+
+func Test_FactoryGenerativeConstraint(t *testing.T) {
+	test_generative_constraint_preferred(t)
+	test_generative_constraint_fallback(t)
+	test_generative_constraint_ensure(t)
+	test_generative_constraint_seed(t)
+	test_generative_string_constraint(t)
+}
+
+func test_generative_constraint_preferred(t *testing.T) {
+	c := &evenConstraint{}
+	results := salem.For[ticket]().
+		EnsureConstraint("Number", c).
+		EnsureConstraint("Seat", c).
+		WithExactItems(20).
+		Execute()
+
+	assert.Equal(t, 40, c.generateCalls, "expect Generate(...) to be called for each value")
+	assert.Equal(t, 40, c.validCalls, "expect each generated value to be checked once")
+	for _, r := range results {
+		assert.Equal(t, 0, r.Number%2)
+		assert.Equal(t, int8(0), r.Seat%2, "expect the generated value to be converted to the field's type")
+	}
+}
+
+func test_generative_constraint_fallback(t *testing.T) {
+	c := &evenConstraint{failGenerate: true}
+	results := salem.For[ticket]().
+		EnsureConstraint("Number", c).
+		WithExactItems(20).
+		Execute()
+
+	assert.Equal(t, c.validCalls, c.generateCalls, "expect Generate(...) to be tried for each attempt")
+	assert.True(t, c.validCalls > 20, "expect the plan to retry with the field's generator when Generate(...) fails")
+	for _, r := range results {
+		assert.Equal(t, 0, r.Number%2)
+	}
+}
+
+func test_generative_constraint_ensure(t *testing.T) {
+	c := &evenConstraint{}
+	result := salem.For[ticket]().
+		EnsureSequence("Number", 4).
+		EnsureConstraint("Number", c).
+		One()
+
+	assert.Equal(t, 4, result.Number, "expect the factory options to take precedence over Generate(...)")
+	assert.Equal(t, 0, c.generateCalls)
+}
+
+func test_generative_constraint_seed(t *testing.T) {
+	first := salem.For[ticket]().WithSeed(11).EnsureConstraint("Number", &evenConstraint{}).ExecuteN(5)
+	second := salem.For[ticket]().WithSeed(11).EnsureConstraint("Number", &evenConstraint{}).ExecuteN(5)
+
+	assert.Equal(t, first, second, "expect Generate(...) to use the factory's random source")
+}
+
+func test_generative_string_constraint(t *testing.T) {
+	results := salem.For[ticket]().
+		EnsureConstraint("Code", salem.ConstrainStringLength(80, 82)).
+		EnsureConstraint("Gate", salem.ConstrainStringLength(1, 1)).
+		WithExactItems(20).
+		Execute()
+
+	for _, r := range results {
+		assert.True(t, len(r.Code) >= 80 && len(r.Code) <= 82, "expect lengths beyond the default generator")
+		assert.Equal(t, 1, len(r.Gate), "expect named string types")
+	}
+}
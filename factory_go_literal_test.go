@@ -0,0 +1,110 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"bytes"
+	"go-salem"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixture struct {
+	Name     string
+	Level    int8
+	Ratio    float64
+	Status   accountStatus
+	Created  time.Time
+	Nickname *string
+	Owner    *fixtureOwner
+	Tags     []string
+	Counts   map[string]int
+	Children []*fixtureOwner
+	Extra    interface{}
+	Skipped  string
+}
+
+type fixtureOwner struct {
+	ID int
+}
+
+func Test_FactoryGoLiteral(t *testing.T) {
+	test_go_literal_output(t)
+	test_go_literal_qualifiers(t)
+}
+
+func test_go_literal_output(t *testing.T) {
+	var buf bytes.Buffer
+	err := salem.For[fixture]().
+		Ensure("Name", "Ada \"Lovelace\"").
+		Ensure("Level", int8(3)).
+		Ensure("Ratio", 2.0).
+		Ensure("Status", accountStatus("active")).
+		Ensure("Created", time.Date(2021, 6, 23, 10, 30, 0, 0, time.UTC)).
+		Ensure("Nickname", "ada").
+		Ensure("Owner", fixtureOwner{ID: 7}).
+		Ensure("Tags", []string{"a", "b"}).
+		EnsureMapKeySequence("Counts", "y", "x").
+		EnsureMapValueSequence("Counts", 2, 1).
+		WithExactMapItems("Counts", 2).
+		EnsureSequence("Children", []*fixtureOwner{{ID: 1}, nil}).
+		Ensure("Extra", uint16(9)).
+		Omit("Skipped").
+		WriteGoLiteral(&buf, "fixtures", salem.GoLiteralPackage(reflect.TypeOf(fixture{}).PkgPath()))
+	assert.Nil(t, err)
+
+	expected := `var fixtures = []fixture{
+	{
+		Name:     "Ada \"Lovelace\"",
+		Level:    3,
+		Ratio:    2.0,
+		Status:   "active",
+		Created:  time.Date(2021, time.June, 23, 10, 30, 0, 0, time.UTC),
+		Nickname: fixturesPtr[string]("ada"),
+		Owner: &fixtureOwner{
+			ID: 7,
+		},
+		Tags: []string{
+			"a",
+			"b",
+		},
+		Counts: map[string]int{
+			"x": 1,
+			"y": 2,
+		},
+		Children: []*fixtureOwner{
+			{
+				ID: 1,
+			},
+			nil,
+		},
+		Extra: uint16(9),
+	},
+}
+
+// fixturesPtr returns a pointer to v
+func fixturesPtr[T any](v T) *T { return &v }
+`
+	assert.Equal(t, expected, buf.String(), "expect a gofmt'd composite literal")
+
+	_, err = parser.ParseFile(token.NewFileSet(), "fixtures.go", "package fixtures\n\n"+buf.String(), 0)
+	assert.Nil(t, err, "expect valid Go source")
+}
+
+func test_go_literal_qualifiers(t *testing.T) {
+	var buf bytes.Buffer
+	err := salem.For[fixture]().
+		Ensure("Name", "x").
+		Ensure("Extra", accountStatus("on")).
+		WriteGoLiteral(&buf, "people")
+	assert.Nil(t, err)
+
+	assert.Contains(t, buf.String(), "var people = []salem_test.fixture{", "expect the types to be qualified by their package")
+	assert.Contains(t, buf.String(), `salem_test.accountStatus("on"),`, "expect interface values to be converted to their type")
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lineItem struct {
+	Qty       int
+	UnitPrice int
+	Total     int
+}
+
+type purchase struct {
+	Status    string
+	StartDate time.Time
+	EndDate   time.Time
+	ShippedAt *time.Time
+	Line      lineItem
+	Lines     []lineItem
+}
+
+func Test_FactoryItemConstraints(t *testing.T) {
+	test_ensure_item(t)
+	test_ensure_derived(t)
+	test_ensure_derived_nested(t)
+	test_ensure_item_errors(t)
+}
+
+func test_ensure_item(t *testing.T) {
+	results := salem.For[purchase]().
+		EnsureSequence("Status", "shipped", "pending", "shipped", "pending").
+		EnsureItem(func(p purchase) bool { return p.EndDate.After(p.StartDate) }).
+		EnsureItem(func(p purchase) bool { return p.Line.Qty > p.Line.UnitPrice }).
+		WithExactItems(4).
+		Execute()
+
+	for i, p := range results {
+		assert.True(t, p.EndDate.After(p.StartDate), "expect the item to be re-rolled until it is valid")
+		assert.True(t, p.Line.Qty > p.Line.UnitPrice, "expect the item constraints to stack")
+		assert.Equal(t, []string{"shipped", "pending"}[i%2], p.Status, "expect the item index to be kept when re-rolling")
+	}
+}
+
+func test_ensure_derived(t *testing.T) {
+	results := salem.For[purchase]().
+		EnsureSequence("Status", "shipped", "pending").
+		EnsureDerived("Line.Total", func(p purchase) interface{} { return p.Line.Qty * p.Line.UnitPrice }).
+		EnsureDerived("ShippedAt", func(p purchase) interface{} {
+			if p.Status != "shipped" {
+				return nil
+			}
+			return p.ShippedAt
+		}).
+		EnsureItem(func(p purchase) bool { return p.Line.Total == p.Line.Qty*p.Line.UnitPrice }).
+		WithExactItems(2).
+		Execute()
+
+	assert.Equal(t, results[0].Line.Qty*results[0].Line.UnitPrice, results[0].Line.Total, "expect the derived value")
+	assert.NotNil(t, results[0].ShippedAt)
+	assert.Nil(t, results[1].ShippedAt, "expect nil to set the zero value")
+}
+
+func test_ensure_derived_nested(t *testing.T) {
+	result := salem.For[purchase]().
+		Ensure("Lines", salem.Tap().
+			WithExactItems(3).
+			EnsureDerived("Lines.Total", func(item interface{}) interface{} {
+				line := item.(lineItem)
+				return line.Qty * line.UnitPrice
+			})).
+		One()
+
+	assert.Equal(t, 3, len(result.Lines))
+	for _, line := range result.Lines {
+		assert.Equal(t, line.Qty*line.UnitPrice, line.Total, "expect tapped factories to derive the fields of their items")
+	}
+
+	err := salem.For[purchase]().EnsureDerived("Line.Totl", func(purchase) interface{} { return 0 }).Validate()
+	assert.NotNil(t, err, "expect derived paths to be validated")
+}
+
+func test_ensure_item_errors(t *testing.T) {
+	_, err := salem.For[purchase]().
+		EnsureItem(func(purchase) bool { return false }).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the item can't be generated")
+	assert.Equal(t, "", constraintErr.FieldName)
+	assert.Contains(t, err.Error(), "EnsureItem #1")
+
+	_, err = salem.For[purchase]().
+		EnsureDerived("Line.Total", func(purchase) interface{} { return "many" }).
+		ExecuteE()
+
+	var kindErr *salem.UnsupportedKindError
+	assert.True(t, errors.As(err, &kindErr), "expect an error when the derived value has the wrong type")
+}
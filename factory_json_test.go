@@ -0,0 +1,141 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type apiUser struct {
+	ID       int      `json:"id"`
+	Email    string   `json:"email"`
+	Nickname string   `json:"nickname,omitempty"`
+	Roles    []string `json:"roles"`
+	Password string   `json:"-"`
+	Plain    bool
+}
+
+// failingWriter fails after n writes
+type failingWriter struct {
+	n      int
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes > w.n {
+		return 0, errors.New("disk full")
+	}
+
+	return len(p), nil
+}
+
+func Test_FactoryJSON(t *testing.T) {
+	test_json_tags(t)
+	test_json_indent(t)
+	test_json_lines(t)
+	test_json_errors(t)
+}
+
+func test_json_tags(t *testing.T) {
+	var buf bytes.Buffer
+	err := salem.For[apiUser]().
+		EnsureSequence("Nickname", "", "neo").
+		Ensure("Password", "secret").
+		WithExactItems(2).
+		WriteJSON(&buf, salem.JSONOptions{})
+	assert.Nil(t, err)
+
+	var users []map[string]interface{}
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &users), "expect a JSON array")
+	assert.Equal(t, 2, len(users))
+
+	assert.NotContains(t, users[0], "nickname", "expect omitempty to leave out empty fields")
+	assert.Equal(t, "neo", users[1]["nickname"])
+	assert.NotContains(t, users[0], "Password", "expect fields tagged with - to be left out")
+	assert.Contains(t, users[0], "email", "expect the json tag names")
+	assert.Contains(t, users[0], "Plain", "expect the field name without a json tag")
+
+	buf.Reset()
+	assert.Nil(t, salem.For[apiUser]().WithExactItems(0).WriteJSON(&buf, salem.JSONOptions{}))
+	assert.Equal(t, "[]\n", buf.String(), "expect an empty array")
+}
+
+func test_json_indent(t *testing.T) {
+	var buf bytes.Buffer
+	err := salem.For[apiUser]().
+		EnsureSequence("ID", 1, 2).
+		Ensure("Email", "a@b.com").
+		Ensure("Roles", []string{"admin"}).
+		Ensure("Plain", true).
+		Omit("Nickname").
+		WithExactItems(2).
+		WriteJSON(&buf, salem.JSONOptions{Indent: "  "})
+	assert.Nil(t, err)
+
+	expected := `[
+  {
+    "id": 1,
+    "email": "a@b.com",
+    "roles": [
+      "admin"
+    ],
+    "Plain": true
+  },
+  {
+    "id": 2,
+    "email": "a@b.com",
+    "roles": [
+      "admin"
+    ],
+    "Plain": true
+  }
+]
+`
+	assert.Equal(t, expected, buf.String(), "expect the items to be indented like json.MarshalIndent")
+}
+
+func test_json_lines(t *testing.T) {
+	var buf bytes.Buffer
+	err := salem.For[apiUser]().WithExactItems(5).WriteJSONLines(&buf)
+	assert.Nil(t, err)
+
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var user apiUser
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &user), "expect a JSON object per line")
+		lines++
+	}
+
+	assert.Equal(t, 5, lines)
+}
+
+func test_json_errors(t *testing.T) {
+	w := &failingWriter{n: 2}
+	err := salem.For[apiUser]().WithExactItems(100).WriteJSONLines(w)
+
+	assert.EqualError(t, err, "disk full", "expect the writer's error")
+	assert.Equal(t, 3, w.writes, "expect the items to be streamed and to stop at the first error")
+
+	calls := 0
+	err = salem.For[apiUser]().
+		EnsureItem(func(u apiUser) bool {
+			calls++
+			return calls <= 3
+		}).
+		WithExactItems(10).
+		WriteJSON(&failingWriter{n: 100}, salem.JSONOptions{})
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect the generation errors")
+	assert.Equal(t, 3, constraintErr.ItemIndex)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"fmt"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sku mocks itself with a value receiver
+type sku string
+
+func (sku) SalemMock(ctx salem.GenContext) interface{} {
+	return sku(fmt.Sprintf("SKU-%04d", ctx.Rand.Intn(10000)))
+}
+
+// price mocks itself with a pointer receiver and returns a pointer
+type price struct {
+	Cents    int
+	Currency string
+}
+
+func (*price) SalemMock(ctx salem.GenContext) interface{} {
+	return &price{Cents: 100 * (ctx.ItemIndex + 1), Currency: "JMD"}
+}
+
+// label records the context it was called with
+type label struct {
+	Field string
+}
+
+func (label) SalemMock(ctx salem.GenContext) interface{} {
+	return label{Field: ctx.FieldName}
+}
+
+type product struct {
+	SKU      sku
+	Price    price
+	Sale     *price
+	Label    label
+	Variants []sku
+	Prices   map[sku]price
+	Parent   *product
+}
+
+func Test_FactoryMocker(t *testing.T) {
+	test_mocker_fields(t)
+	test_mocker_context(t)
+	test_mocker_nested(t)
+	test_mocker_precedence(t)
+	test_mocker_root(t)
+}
+
+func test_mocker_fields(t *testing.T) {
+	results, err := salem.For[product]().
+		Omit("Parent").
+		WithExactItems(2).
+		ExecuteE()
+
+	assert.Nil(t, err)
+	assert.Regexp(t, `^SKU-\d{4}$`, string(results[0].SKU), "expect value receiver SalemMock(...) to be used")
+	assert.Equal(t, price{Cents: 100, Currency: "JMD"}, results[0].Price, "expect pointer receiver SalemMock(...) to be used")
+	assert.Equal(t, price{Cents: 200, Currency: "JMD"}, *results[1].Sale, "expect SalemMock(...) to be used for pointers")
+}
+
+func test_mocker_context(t *testing.T) {
+	result := salem.For[product]().
+		Omit("Parent").
+		One()
+
+	assert.Equal(t, "Label", result.Label.Field, "expect the qualified field name in the context")
+
+	first := salem.For[product]().Omit("Parent").WithSeed(7).One()
+	second := salem.For[product]().Omit("Parent").WithSeed(7).One()
+	assert.Equal(t, first.SKU, second.SKU, "expect the context to use the factory's random source")
+}
+
+func test_mocker_nested(t *testing.T) {
+	result := salem.For[product]().
+		Omit("Parent.Parent").
+		Ensure("Variants", salem.Tap().WithExactItems(3)).
+		WithExactMapItems("Prices", 2).
+		One()
+
+	assert.Regexp(t, `^SKU-\d{4}$`, string(result.Variants[2]), "expect SalemMock(...) for slice elements")
+	assert.Regexp(t, `^SKU-\d{4}$`, string(result.Parent.SKU), "expect SalemMock(...) for nested fields")
+	assert.Equal(t, "Parent.Label", result.Parent.Label.Field)
+
+	assert.NotEmpty(t, result.Prices)
+	for key, value := range result.Prices {
+		assert.Regexp(t, `^SKU-\d{4}$`, string(key), "expect SalemMock(...) for map keys")
+		assert.Equal(t, "JMD", value.Currency, "expect SalemMock(...) for map values")
+	}
+}
+
+func test_mocker_precedence(t *testing.T) {
+	result := salem.For[product]().
+		Omit("Parent").
+		Ensure("SKU", sku("FIXED")).
+		One()
+
+	assert.Equal(t, sku("FIXED"), result.SKU, "expect Ensure(...) to take precedence")
+}
+
+func test_mocker_root(t *testing.T) {
+	results := salem.For[*price]().WithExactItems(2).Execute()
+
+	assert.Equal(t, 200, results[1].Cents, "expect SalemMock(...) for the factory's type")
+}
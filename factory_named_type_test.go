@@ -0,0 +1,88 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type accountStatus string
+type customerID int32
+type priority uint8
+type ratio float32
+type flag bool
+
+type customer struct {
+	ID        customerID
+	Status    accountStatus
+	Priority  priority
+	Discount  ratio
+	IsActive  flag
+	Previous  *accountStatus
+	Referrals []customerID
+	Tags      []*accountStatus
+	Scores    map[accountStatus]ratio
+	Flags     map[customerID]*flag
+	Level     int8
+}
+
+func Test_FactoryNamedTypes(t *testing.T) {
+	test_named_type_fields(t)
+	test_named_type_ensure(t)
+	test_named_type_map_sequences(t)
+	test_named_type_constraint(t)
+}
+
+func test_named_type_fields(t *testing.T) {
+	result, err := salem.For[customer]().
+		Ensure("Tags", salem.Tap().WithExactItems(2)).
+		ExecuteE()
+
+	assert.Nil(t, err, "expect named types to be converted")
+
+	c := result[0]
+	assert.NotEmpty(t, c.Status)
+	assert.NotNil(t, c.Previous, "expect pointers to named types")
+	assert.Equal(t, 1, len(c.Referrals), "expect slices of named types")
+	assert.Equal(t, 2, len(c.Tags), "expect slices of pointers to named types")
+	assert.Equal(t, 1, len(c.Scores), "expect maps of named types")
+	assert.Equal(t, 1, len(c.Flags), "expect maps with pointers to named types")
+}
+
+func test_named_type_ensure(t *testing.T) {
+	results := salem.For[customer]().
+		Ensure("Status", "active").
+		EnsureSequence("ID", 1001, 1002).
+		Ensure("Level", 3).
+		OnField("Priority", func(itemIndex int) interface{} { return itemIndex + 1 }).
+		WithExactItems(2).
+		Execute()
+
+	assert.Equal(t, accountStatus("active"), results[0].Status, "expect Ensure(...) values to be converted")
+	assert.Equal(t, customerID(1002), results[1].ID, "expect EnsureSequence(...) values to be converted")
+	assert.Equal(t, int8(3), results[0].Level, "expect int values to be converted to int8")
+	assert.Equal(t, priority(2), results[1].Priority, "expect OnField(...) values to be converted")
+}
+
+func test_named_type_map_sequences(t *testing.T) {
+	result := salem.For[customer]().
+		EnsureMapKeySequence("Scores", "gold", "silver").
+		EnsureMapValueSequence("Scores", 0.5, 0.25).
+		WithExactMapItems("Scores", 2).
+		One()
+
+	assert.Equal(t, ratio(0.5), result.Scores["gold"], "expect map sequences to be converted")
+	assert.Equal(t, ratio(0.25), result.Scores["silver"])
+}
+
+func test_named_type_constraint(t *testing.T) {
+	result := salem.For[customer]().
+		EnsureConstraint("Status", salem.ConstrainStringLength(3, 10)).
+		One()
+
+	assert.True(t, len(result.Status) >= 3 && len(result.Status) <= 10, "expect constraints to work with named types")
+}
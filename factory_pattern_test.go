@@ -0,0 +1,167 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type warehouse struct {
+	Code     string `salem:"len=3"`
+	Phone    *string
+	Aisles   []string
+	Bins     map[string]string
+	Counts   map[string]int
+	Location location
+	Capacity int
+	Docks    [2]string
+}
+
+type location struct {
+	Postcode string
+}
+
+type emptyClassTag struct {
+	Code string `salem:"regex=x[^\\x00-\\x{10FFFF}]"`
+}
+
+var (
+	codePattern     = regexp.MustCompile(`^WH-[0-9]{3}$`)
+	phonePattern    = regexp.MustCompile(`\+1 \(\d{3}\) \d{3}-\d{4}`)
+	aislePattern    = regexp.MustCompile(`(A|B|C)[1-9]`)
+	binPattern      = regexp.MustCompile(`BIN-[a-f0-9]{4}`)
+	postcodePattern = regexp.MustCompile(`[A-Z]{2}[0-9] [0-9][A-Z]{2}`)
+)
+
+func Test_FactoryPattern(t *testing.T) {
+	test_pattern_fields(t)
+	test_pattern_map_keys_and_values(t)
+	test_pattern_with_constraint(t)
+	test_pattern_validate(t)
+	test_pattern_empty_class(t)
+	test_pattern_array_elements(t)
+}
+
+func test_pattern_fields(t *testing.T) {
+	results := salem.For[warehouse]().
+		EnsurePattern("Code", codePattern).
+		EnsurePattern("Phone", phonePattern).
+		EnsurePattern("Aisles", aislePattern).
+		EnsurePattern("Location.Postcode", postcodePattern).
+		WithExactItems(10).
+		Execute()
+
+	for _, w := range results {
+		assert.Regexp(t, `^WH-[0-9]{3}$`, w.Code, "expect EnsurePattern(...) to take precedence over the tag")
+		assert.Regexp(t, `^\+1 \(\d{3}\) \d{3}-\d{4}$`, *w.Phone, "expect the pattern to apply to the pointer's value")
+		assert.Regexp(t, `^[A-Z]{2}[0-9] [0-9][A-Z]{2}$`, w.Location.Postcode, "expect the pattern to apply to nested fields")
+
+		for _, aisle := range w.Aisles {
+			assert.Regexp(t, `^(A|B|C)[1-9]$`, aisle, "expect the pattern to apply to the slice elements")
+		}
+	}
+}
+
+func test_pattern_map_keys_and_values(t *testing.T) {
+	results := salem.For[warehouse]().
+		EnsureMapKeyPattern("Bins", binPattern).
+		EnsureMapValuePattern("Bins", aislePattern).
+		EnsureMapKeyPattern("Counts", aislePattern).
+		WithExactMapItems("Bins", 5).
+		WithExactMapItems("Counts", 5).
+		WithExactItems(5).
+		Execute()
+
+	for _, w := range results {
+		assert.Equal(t, 5, len(w.Bins), "expect unique keys from the pattern")
+		assert.Equal(t, 5, len(w.Counts), "expect unique keys from the pattern")
+
+		for key, value := range w.Bins {
+			assert.Regexp(t, `^BIN-[a-f0-9]{4}$`, key, "expect EnsureMapKeyPattern(...) to generate the keys")
+			assert.Regexp(t, `^(A|B|C)[1-9]$`, value, "expect EnsureMapValuePattern(...) to generate the values")
+		}
+		for key := range w.Counts {
+			assert.Regexp(t, `^(A|B|C)[1-9]$`, key, "expect EnsureMapKeyPattern(...) to generate the keys")
+		}
+	}
+
+	_, err := salem.For[warehouse]().
+		EnsureMapKeyPattern("Bins", regexp.MustCompile(`[AB]`)).
+		WithExactMapItems("Bins", 3).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the pattern runs out of keys")
+}
+
+func test_pattern_with_constraint(t *testing.T) {
+	results := salem.For[warehouse]().
+		EnsurePattern("Code", regexp.MustCompile(`[A-Z]{1,6}`)).
+		EnsureConstraint("Code", salem.ConstrainStringLength(4, 6)).
+		WithExactItems(10).
+		Execute()
+
+	for _, w := range results {
+		assert.Regexp(t, `^[A-Z]{4,6}$`, w.Code, "expect the constraint to check the values of the pattern")
+	}
+}
+
+func test_pattern_validate(t *testing.T) {
+	err := salem.For[warehouse]().
+		EnsurePattern("Code", codePattern).
+		EnsurePattern("Aisles", aislePattern).
+		EnsureMapKeyPattern("Bins", binPattern).
+		EnsureMapValuePattern("Bins", binPattern).
+		Validate()
+	assert.Nil(t, err, "expect string fields to pass validation")
+
+	err = salem.For[warehouse]().
+		EnsurePattern("Capacity", codePattern).
+		EnsurePattern("Adress", codePattern).
+		EnsureMapValuePattern("Counts", binPattern).
+		EnsureMapKeyPattern("Code", binPattern).
+		Validate()
+
+	var validationErr *salem.ValidationError
+	assert.True(t, errors.As(err, &validationErr), "expect a *ValidationError")
+	assert.Equal(t, 4, len(validationErr.Errors), "expect non-string fields and unknown paths to be reported")
+}
+
+func test_pattern_empty_class(t *testing.T) {
+	emptyClass := regexp.MustCompile(`x[^\x00-\x{10FFFF}]`)
+
+	f := salem.For[warehouse]().EnsurePattern("Code", emptyClass).EnsureMapKeyPattern("Bins", emptyClass)
+	expected := "EnsurePattern: Can't generate strings for the pattern 'x[^\\x00-\\x{10FFFF}]': a character class matches no characters. Field: 'Code'"
+
+	var validationErr *salem.ValidationError
+	assert.True(t, errors.As(f.Validate(), &validationErr), "expect Validate() to report the pattern")
+	assert.Equal(t, 2, len(validationErr.Errors), "expect every pattern that matches nothing to be reported")
+	assert.Equal(t, expected, validationErr.Errors[1].Error())
+
+	var pathErr *salem.FieldPathError
+	_, err := f.ExecuteE()
+	assert.True(t, errors.As(err, &pathErr), "expect classes that match nothing to be rejected with a *FieldPathError")
+	assert.Equal(t, expected, err.Error())
+
+	_, err = salem.For[emptyClassTag]().ExecuteE()
+	assert.True(t, errors.As(err, &pathErr), "expect the tag to be rejected with a *FieldPathError")
+	assert.Contains(t, err.Error(), "matches no characters")
+}
+
+func test_pattern_array_elements(t *testing.T) {
+	results := salem.For[warehouse]().
+		EnsurePattern("Docks[0]", codePattern).
+		WithExactItems(10).
+		Execute()
+
+	for _, w := range results {
+		assert.Regexp(t, codePattern, w.Docks[0], "expect EnsurePattern(...) to apply to the array element")
+		assert.NotRegexp(t, codePattern, w.Docks[1], "expect the other elements to be random")
+	}
+}
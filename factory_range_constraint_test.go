@@ -0,0 +1,105 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reading struct {
+	Sensor      int
+	Level       int8
+	Temperature float64
+	Pressure    float32
+	Count       uint32
+	Port        uint16
+	Offset      int32
+	Grade       priority
+}
+
+func Test_FactoryRangeConstraints(t *testing.T) {
+	test_int_range_constraint(t)
+	test_uint_range_constraint(t)
+	test_float_range_constraint(t)
+	test_range_constraint_across_kinds(t)
+	test_range_constraint_errors(t)
+}
+
+func test_int_range_constraint(t *testing.T) {
+	results := salem.For[reading]().
+		EnsureConstraint("Sensor", salem.ConstrainIntRange(10000, 20000)).
+		EnsureConstraint("Level", salem.ConstrainIntRange(-5, 5)).
+		EnsureConstraint("Offset", salem.ConstrainIntRange(-1<<30, -1<<29)).
+		WithExactItems(50).
+		Execute()
+
+	for _, r := range results {
+		assert.True(t, r.Sensor >= 10000 && r.Sensor <= 20000, "expect the value to be generated within the range")
+		assert.True(t, r.Level >= -5 && r.Level <= 5, "expect negative ranges")
+		assert.True(t, r.Offset >= -1<<30 && r.Offset <= -1<<29, "expect ranges beyond the default generator")
+	}
+}
+
+func test_uint_range_constraint(t *testing.T) {
+	results := salem.For[reading]().
+		EnsureConstraint("Count", salem.ConstrainUintRange(4000000000, 4000000010)).
+		EnsureConstraint("Port", salem.ConstrainUintRange(1024, 1030)).
+		WithExactItems(50).
+		Execute()
+
+	for _, r := range results {
+		assert.True(t, r.Count >= 4000000000 && r.Count <= 4000000010)
+		assert.True(t, r.Port >= 1024 && r.Port <= 1030)
+	}
+}
+
+func test_float_range_constraint(t *testing.T) {
+	results := salem.For[reading]().
+		EnsureConstraint("Temperature", salem.ConstrainFloatRange(-40.5, -39.5)).
+		EnsureConstraint("Pressure", salem.ConstrainFloatRange(1000, 1001)).
+		WithExactItems(50).
+		Execute()
+
+	for _, r := range results {
+		assert.True(t, r.Temperature >= -40.5 && r.Temperature <= -39.5)
+		assert.True(t, r.Pressure >= 1000 && r.Pressure <= 1001)
+	}
+}
+
+func test_range_constraint_across_kinds(t *testing.T) {
+	results := salem.For[reading]().
+		EnsureConstraint("Temperature", salem.ConstrainIntRange(20, 25)).
+		EnsureConstraint("Port", salem.ConstrainIntRange(-10, 2)).
+		EnsureConstraint("Sensor", salem.ConstrainFloatRange(1.5, 3.5)).
+		EnsureConstraint("Grade", salem.ConstrainIntRange(1, 3)).
+		WithExactItems(50).
+		Execute()
+
+	for _, r := range results {
+		assert.True(t, r.Temperature >= 20 && r.Temperature <= 25, "expect int ranges on float fields")
+		assert.True(t, r.Port <= 2, "expect the range to be narrowed to the unsigned values")
+		assert.True(t, r.Sensor >= 2 && r.Sensor <= 3, "expect whole numbers for int fields")
+		assert.True(t, r.Grade >= 1 && r.Grade <= 3, "expect named types")
+	}
+}
+
+func test_range_constraint_errors(t *testing.T) {
+	_, err := salem.For[reading]().
+		EnsureConstraint("Level", salem.ConstrainIntRange(10000, 20000)).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the field can't hold the range")
+	assert.Contains(t, err.Error(), "IntRange[10000, 20000]")
+
+	_, err = salem.For[reading]().
+		Ensure("Sensor", 5).
+		EnsureConstraint("Sensor", salem.ConstrainIntRange(10, 20)).
+		ExecuteE()
+	assert.True(t, errors.As(err, &constraintErr), "expect Ensure(...) values to still be checked")
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type seededTrade struct {
+	GUID     string
+	Qty      int
+	Price    float64
+	IsOpen   bool
+	Broker   *school
+	Tags     []string
+	Counters map[string]int
+}
+
+func Test_FactorySeed(t *testing.T) {
+	test_same_seed_same_mocks(t)
+	test_seed_is_reported(t)
+	test_seed_replays_run(t)
+	test_seed_int64_fields(t)
+}
+
+func newSeededTradeFactory() *salem.Factory {
+	return salem.Mock(seededTrade{}).
+		Ensure("Tags", salem.Tap().WithMaxItems(6)).
+		WithMaxMapItems("Counters", 6).
+		WithMinItems(3)
+}
+
+func test_same_seed_same_mocks(t *testing.T) {
+	first := newSeededTradeFactory().WithSeed(2021).Execute()
+	second := newSeededTradeFactory().WithSeed(2021).Execute()
+
+	assert.Equal(t, first, second, "expect the same seed to generate the same mocks")
+
+	f := newSeededTradeFactory().WithSeed(2021)
+	assert.Equal(t, f.Execute(), f.Execute(), "expect each Execute to replay the seed")
+}
+
+func test_seed_is_reported(t *testing.T) {
+	f := newSeededTradeFactory().WithSeed(42)
+	f.Execute()
+
+	assert.Equal(t, int64(42), f.Seed(), "expect Seed() to report the seed from WithSeed(...)")
+}
+
+func test_seed_replays_run(t *testing.T) {
+	f := newSeededTradeFactory()
+	expected := f.Execute()
+
+	actual := newSeededTradeFactory().WithSeed(f.Seed()).Execute()
+
+	assert.Equal(t, expected, actual, "expect the reported seed to replay the run")
+}
+
+type ledgerEntry struct {
+	Sequence int64
+}
+
+func test_seed_int64_fields(t *testing.T) {
+	var entries []interface{}
+	assert.NotPanics(t, func() {
+		entries = salem.Mock(ledgerEntry{}).WithSeed(7).WithExactItems(20).Execute()
+	}, "expect int64 fields to be generated")
+
+	for _, entry := range entries {
+		assert.GreaterOrEqual(t, entry.(ledgerEntry).Sequence, int64(0), "expect non-negative int64 values")
+	}
+}
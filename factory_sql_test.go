@@ -0,0 +1,126 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"bytes"
+	"go-salem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type Audit struct {
+	UpdatedBy string
+}
+
+type dbUser struct {
+	ID        int `db:"user_id"`
+	FullName  string
+	Email     string `gorm:"column:email_address;unique"`
+	IsAdmin   bool
+	CreatedAt time.Time
+	Manager   *string
+	Address   address
+	Previous  *address
+	Tags      []string
+	Internal  string `db:"-"`
+	HTTPPort  uint16
+	Audit
+}
+
+func Test_FactorySQL(t *testing.T) {
+	test_sql_postgres(t)
+	test_sql_dialects(t)
+	test_sql_nested_json(t)
+	test_sql_omit_and_errors(t)
+}
+
+// sqlUsers is a factory with fixed values, except for the pointers which are nil
+func sqlUsers() *salem.TypedFactory[dbUser] {
+	return salem.For[dbUser]().
+		EnsureSequence("ID", 1, 2, 3).
+		Ensure("FullName", `O'Brien \ Co`).
+		Ensure("Email", "a@b.com").
+		Ensure("IsAdmin", true).
+		Ensure("CreatedAt", time.Date(2021, 6, 23, 10, 30, 0, 0, time.FixedZone("EST", -5*3600))).
+		Ensure("Address", address{Street: "Main", Zip: "1"}).
+		Ensure("Tags", []string{"a"}).
+		Ensure("HTTPPort", uint16(8080)).
+		Ensure("Audit.UpdatedBy", "ops").
+		EnsureDerived("Manager", func(u dbUser) interface{} { return nil }).
+		EnsureDerived("Previous", func(u dbUser) interface{} { return nil }).
+		WithExactItems(3)
+}
+
+func test_sql_postgres(t *testing.T) {
+	var buf bytes.Buffer
+	err := sqlUsers().WriteSQL(&buf, "public.users", salem.Postgres, salem.SQLBatchSize(2))
+	assert.Nil(t, err)
+
+	columns := `"user_id", "full_name", "email_address", "is_admin", "created_at", "manager", "address_street", "address_zip", ` +
+		`"previous_street", "previous_zip", "tags", "http_port", "updated_by"`
+	row := `'O''Brien \ Co', 'a@b.com', TRUE, '2021-06-23 15:30:00', NULL, 'Main', '1', NULL, NULL, '["a"]', 8080, 'ops')`
+
+	expected := `INSERT INTO "public"."users" (` + columns + `) VALUES
+(1, ` + row + `,
+(2, ` + row + `;
+INSERT INTO "public"."users" (` + columns + `) VALUES
+(3, ` + row + `;
+`
+	assert.Equal(t, expected, buf.String(), "expect batched INSERT statements")
+}
+
+func test_sql_dialects(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Nil(t, sqlUsers().WithExactItems(1).WriteSQL(&buf, "users", salem.MySQL))
+
+	assert.True(t, strings.HasPrefix(buf.String(), "INSERT INTO `users` (`user_id`, `full_name`"), "expect MySQL to quote with backticks")
+	assert.Contains(t, buf.String(), `'O''Brien \\ Co', 'a@b.com', TRUE`, "expect MySQL to escape backslashes")
+
+	buf.Reset()
+	assert.Nil(t, sqlUsers().WithExactItems(1).WriteSQL(&buf, "users", salem.SQLite))
+
+	assert.True(t, strings.HasPrefix(buf.String(), `INSERT INTO "users" ("user_id"`))
+	assert.Contains(t, buf.String(), `'O''Brien \ Co', 'a@b.com', 1,`, "expect SQLite booleans to be 1 or 0")
+}
+
+func test_sql_nested_json(t *testing.T) {
+	var buf bytes.Buffer
+	err := salem.For[dbUser]().
+		Ensure("Address", address{Street: "Main", Zip: "1"}).
+		Ensure("Previous", address{Street: "Old", Zip: "2"}).
+		WriteSQL(&buf, "users", salem.Postgres, salem.SQLNestedJSON())
+	assert.Nil(t, err)
+
+	assert.Contains(t, buf.String(), `"manager", "address", "previous", "tags", "http_port", "updated_by")`,
+		"expect a column per nested struct and the embedded struct to be flattened")
+	assert.Contains(t, buf.String(), `'{"Street":"Main","Zip":"1"}', '{"Street":"Old","Zip":"2"}'`, "expect the nested structs as JSON")
+}
+
+func test_sql_omit_and_errors(t *testing.T) {
+	var buf bytes.Buffer
+	err := sqlUsers().Omit("FullName").Omit("Address.Zip").WithExactItems(1).WriteSQL(&buf, "users", salem.Postgres)
+	assert.Nil(t, err)
+
+	assert.NotContains(t, buf.String(), `"full_name"`, "expect omitted fields to be left out")
+	assert.NotContains(t, buf.String(), `"address_zip"`, "expect omitted nested fields to be left out")
+	assert.NotContains(t, buf.String(), `"internal"`, "expect fields tagged with - to be left out")
+
+	err = sqlUsers().WriteSQL(&buf, "users", salem.SQLDialect(9))
+	assert.EqualError(t, err, "unsupported SQL dialect SQLDialect(9)")
+
+	type flags struct {
+		Name    string
+		Enabled map[bool]int
+	}
+
+	buf.Reset()
+	err = salem.For[flags]().WriteSQL(&buf, "flags", salem.Postgres)
+	assert.NotNil(t, err, "expect values that can't be JSON strings to fail")
+	assert.Contains(t, err.Error(), "'enabled'", "expect the column to be reported")
+	assert.Empty(t, buf.String(), "expect the failed batch not to be written")
+}
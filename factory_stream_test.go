@@ -0,0 +1,173 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type metric struct {
+	ID    int
+	Name  string
+	Value float64
+}
+
+func Test_FactoryStream(t *testing.T) {
+	test_stream_items(t)
+	test_stream_unbounded(t)
+	test_stream_unbounded_collected(t)
+	test_stream_all(t)
+	test_stream_errors(t)
+	test_stream_panics(t)
+}
+
+func test_stream_items(t *testing.T) {
+	factory := salem.For[metric]().WithSeed(7).WithExactItems(50)
+	expected := factory.Execute()
+
+	var streamed []metric
+	for item := range factory.Stream(context.Background()) {
+		streamed = append(streamed, item)
+	}
+
+	assert.Nil(t, factory.Err())
+	assert.Equal(t, expected, streamed, "expect the same items as Execute for the same seed")
+
+	count := 0
+	for item := range salem.Mock(&metric{}).WithExactItems(3).Stream(context.Background()) {
+		_, ok := item.(metric)
+		assert.True(t, ok, "expect the untyped stream to send the plan's values")
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+func test_stream_unbounded(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	factory := salem.For[metric]().Ensure("Name", "cpu").WithUnboundedItems()
+
+	items := factory.Stream(ctx)
+	for i := 0; i < 10000; i++ {
+		item, ok := <-items
+		if !ok || item.Name != "cpu" {
+			assert.Fail(t, "expect the unbounded stream to keep sending items", "item %v", i)
+			break
+		}
+	}
+	cancel()
+
+	for range items {
+		// Drain the items sent before the cancellation was seen
+	}
+
+	assert.True(t, errors.Is(factory.Err(), context.Canceled), "expect the context's error")
+}
+
+func test_stream_unbounded_collected(t *testing.T) {
+	generated := 0
+	factory := salem.For[metric]().
+		EnsureDerived("Name", func(m metric) interface{} {
+			generated++
+			return "cpu"
+		}).
+		WithUnboundedItems()
+	var unboundedErr *salem.UnboundedError
+
+	_, err := factory.ExecuteE()
+	assert.True(t, errors.As(err, &unboundedErr), "expect ExecuteE() to return an *UnboundedError")
+	assert.Zero(t, generated, "expect no item to be generated")
+
+	err = factory.WriteCSV(&bytes.Buffer{}, salem.CSVOptions{})
+	assert.True(t, errors.As(err, &unboundedErr), "expect WriteCSV(...) to return an *UnboundedError")
+
+	err = factory.WriteGoLiteral(&bytes.Buffer{}, "metrics")
+	assert.True(t, errors.As(err, &unboundedErr), "expect WriteGoLiteral(...) to return an *UnboundedError")
+
+	assert.Panics(t, func() { factory.Execute() }, "expect Execute() to panic")
+}
+
+func test_stream_all(t *testing.T) {
+	factory := salem.For[metric]().WithSeed(3).WithExactItems(20)
+	expected := factory.Execute()
+
+	var items []metric
+	factory.All()(func(item metric) bool {
+		items = append(items, item)
+		return true
+	})
+	assert.Equal(t, expected, items, "expect the same items as Execute for the same seed")
+
+	count := 0
+	unbounded := salem.Mock(metric{}).WithUnboundedItems()
+	unbounded.All()(func(item interface{}) bool {
+		count++
+		return count < 5000
+	})
+
+	assert.Equal(t, 5000, count, "expect the iteration to stop when yield returns false")
+	assert.Nil(t, unbounded.Err(), "expect stopping early not to be an error")
+}
+
+func test_stream_errors(t *testing.T) {
+	calls := 0
+	factory := salem.For[metric]().
+		EnsureItem(func(m metric) bool {
+			calls++
+			return calls <= 2
+		}).
+		WithExactItems(10)
+
+	count := 0
+	for range factory.Stream(context.Background()) {
+		count++
+	}
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(factory.Err(), &constraintErr), "expect the generation error")
+	assert.Equal(t, 2, count, "expect the items before the error")
+
+	calls = 0
+	factory.All()(func(item metric) bool { return true })
+	assert.True(t, errors.As(factory.Err(), &constraintErr), "expect the generation error")
+
+	factory.WithExactItems(1)
+	calls = 0
+	factory.All()(func(item metric) bool { return true })
+	assert.Nil(t, factory.Err(), "expect the error of the most recent run")
+}
+
+func test_stream_panics(t *testing.T) {
+	errDerive := errors.New("derive failed")
+	calls := 0
+
+	factory := salem.For[metric]().
+		EnsureDerived("Name", func(m metric) interface{} {
+			calls++
+			if calls == 3 {
+				panic(errDerive)
+			}
+			return "cpu"
+		}).
+		WithExactItems(10)
+
+	count := 0
+	for range factory.Stream(context.Background()) {
+		count++
+	}
+
+	assert.Equal(t, 2, count, "expect the items before the panic")
+	assert.True(t, errors.Is(factory.Err(), errDerive), "expect the panic to be the stream's error")
+
+	factory = salem.For[metric]().EnsureDerived("Name", func(m metric) interface{} { panic("boom") })
+	for range factory.Stream(context.Background()) {
+		assert.Fail(t, "expect no items")
+	}
+	assert.ErrorContains(t, factory.Err(), "boom", "expect panics that aren't errors to be reported")
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type shipment struct {
+	ID       string            `salem:"regex=SHP-[0-9]{4}-[A-Z]{2}"`
+	Contact  string            `salem:"gen=email"`
+	Tracking string            `salem:"gen=uuid"`
+	Carrier  string            `salem:"oneof=dhl|fedex|ups"`
+	Code     string            `salem:"len=6"`
+	Note     string            `salem:"min=2,max=4"`
+	Weight   float64           `salem:"min=1.5,max=2.5"`
+	Boxes    uint16            `salem:"min=10000,max=20000"`
+	Priority priority          `salem:"oneof=1|2|3"`
+	Offset   int8              `salem:"min=-10,max=-5"`
+	Internal string            `salem:"omit"`
+	Parcels  []parcel          `salem:"items=2..4"`
+	Labels   []string          `salem:"items=3,len=5"`
+	Scores   map[string]int    `salem:"items=4,min=90,max=99"`
+	Insured  *bool             `salem:"nil=1"`
+	Backup   *int              `salem:"min=7,max=7"`
+	Extras   map[string]string `salem:"nil=0"`
+}
+
+type parcel struct {
+	Size string `salem:"oneof=S|M|L"`
+	Grid [2]int `salem:"min=1,max=3"`
+}
+
+type badTags struct {
+	Name   string `salem:"min=5,max=2"`
+	Count  int    `salem:"gen=email"`
+	Items  []int  `salem:"items=many"`
+	Amount int    `salem:"nil=0.5"`
+	Other  string `salem:"colour=red"`
+}
+
+func Test_FactoryTags(t *testing.T) {
+	test_tags_string_directives(t)
+	test_tags_numeric_directives(t)
+	test_tags_items(t)
+	test_tags_omit_and_nil(t)
+	test_tags_factory_precedence(t)
+	test_tags_validate(t)
+}
+
+func test_tags_string_directives(t *testing.T) {
+	results := salem.For[shipment]().WithExactItems(10).Execute()
+
+	for _, s := range results {
+		assert.Regexp(t, `^SHP-[0-9]{4}-[A-Z]{2}$`, s.ID, "expect regex= to generate matching strings")
+		assert.Regexp(t, `^[a-z]+@[a-z]+\.com$`, s.Contact, "expect gen=email to generate emails")
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, s.Tracking, "expect gen=uuid to generate UUIDs")
+		assert.Contains(t, []string{"dhl", "fedex", "ups"}, s.Carrier, "expect oneof= to pick one of the options")
+		assert.Equal(t, 6, len(s.Code), "expect len= to set the string length")
+		assert.True(t, len(s.Note) >= 2 && len(s.Note) <= 4, "expect min= and max= to limit the string length")
+	}
+}
+
+func test_tags_numeric_directives(t *testing.T) {
+	results := salem.For[shipment]().WithExactItems(10).Execute()
+
+	for _, s := range results {
+		assert.True(t, s.Weight >= 1.5 && s.Weight < 2.5, "expect min= and max= to limit floats")
+		assert.True(t, s.Boxes >= 10000 && s.Boxes <= 20000, "expect the range to be generated directly")
+		assert.Contains(t, []priority{1, 2, 3}, s.Priority, "expect oneof= to be converted to the named type")
+		assert.True(t, s.Offset >= -10 && s.Offset <= -5, "expect negative ranges")
+		assert.Equal(t, 7, *s.Backup, "expect the directives to apply to the pointer's value")
+	}
+}
+
+func test_tags_items(t *testing.T) {
+	results := salem.For[shipment]().WithExactItems(5).Execute()
+
+	for _, s := range results {
+		assert.True(t, len(s.Parcels) >= 2 && len(s.Parcels) <= 4, "expect items= to set the slice length")
+		assert.Equal(t, 4, len(s.Scores), "expect items= to set the map length")
+		assert.Equal(t, 3, len(s.Labels))
+
+		for _, label := range s.Labels {
+			assert.Equal(t, 5, len(label), "expect the directives to apply to the slice elements")
+		}
+		for _, score := range s.Scores {
+			assert.True(t, score >= 90 && score <= 99, "expect the directives to apply to the map values")
+		}
+		for _, p := range s.Parcels {
+			assert.Contains(t, []string{"S", "M", "L"}, p.Size, "expect the tags of nested structs")
+			assert.True(t, p.Grid[0] >= 1 && p.Grid[1] <= 3, "expect the directives to apply to the array elements")
+		}
+	}
+}
+
+func test_tags_omit_and_nil(t *testing.T) {
+	s := salem.For[shipment]().One()
+
+	assert.Empty(t, s.Internal, "expect omit to skip the field")
+	assert.Nil(t, s.Insured, "expect nil=1 to leave the field nil")
+	assert.NotNil(t, s.Extras, "expect nil=0 to generate the field")
+}
+
+func test_tags_factory_precedence(t *testing.T) {
+	s := salem.For[shipment]().
+		Ensure("Carrier", "post").
+		Ensure("Internal", "visible").
+		Ensure("Insured", true).
+		EnsureSequence("Parcels", []parcel{{Size: "XL"}}).
+		WithExactMapItems("Scores", 1).
+		One()
+
+	assert.Equal(t, "post", s.Carrier, "expect Ensure(...) to override the tag")
+	assert.Equal(t, "visible", s.Internal, "expect Ensure(...) to override omit")
+	assert.True(t, *s.Insured, "expect Ensure(...) to override nil=")
+	assert.Equal(t, "XL", s.Parcels[0].Size, "expect EnsureSequence(...) to override the tag")
+	assert.Equal(t, 1, len(s.Scores), "expect WithExactMapItems(...) to override items=")
+}
+
+func test_tags_validate(t *testing.T) {
+	assert.Nil(t, salem.For[shipment]().Validate(), "expect valid tags to pass validation")
+
+	err := salem.Mock(badTags{}).Validate()
+
+	var validationErr *salem.ValidationError
+	assert.True(t, errors.As(err, &validationErr), "expect a *ValidationError")
+	assert.Equal(t, 5, len(validationErr.Errors), "expect every invalid tag to be reported")
+
+	_, err = salem.Mock(badTags{}).ExecuteE()
+
+	var pathErr *salem.FieldPathError
+	assert.True(t, errors.As(err, &pathErr), "expect a *FieldPathError when the mocks are generated")
+	assert.Equal(t, "Name", pathErr.FieldName)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type auditRecord struct {
+	CreatedAt time.Time
+	UpdatedAt *time.Time
+	Timeout   time.Duration
+	History   []time.Time
+	Delays    map[string]time.Duration
+}
+
+type order struct {
+	GUID   string
+	Audit  auditRecord
+	Events []auditRecord
+}
+
+type shift struct {
+	Times [2]time.Time
+}
+
+func Test_FactoryTime(t *testing.T) {
+	test_time_fields(t)
+	test_time_range(t)
+	test_time_location(t)
+	test_monotonic_time(t)
+	test_time_ensure(t)
+	test_time_validate(t)
+	test_time_array_elements(t)
+}
+
+func test_time_fields(t *testing.T) {
+	result, err := salem.For[auditRecord]().ExecuteE()
+
+	assert.Nil(t, err)
+	assert.False(t, result[0].CreatedAt.IsZero(), "expect time.Time to be mocked")
+	assert.NotNil(t, result[0].UpdatedAt, "expect *time.Time to be mocked")
+	assert.False(t, result[0].UpdatedAt.IsZero(), "expect *time.Time to be mocked")
+	assert.NotEmpty(t, result[0].Timeout, "expect time.Duration to be mocked")
+	assert.False(t, result[0].History[0].IsZero(), "expect []time.Time to be mocked")
+	assert.Equal(t, 1, len(result[0].Delays), "expect time.Duration map values to be mocked")
+}
+
+func test_time_range(t *testing.T) {
+	from := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2021, time.June, 2, 0, 0, 0, 0, time.UTC)
+
+	results := salem.For[order]().
+		WithTimeRange("Audit.CreatedAt", from, to).
+		WithTimeRange("Audit.UpdatedAt", from, to).
+		WithExactItems(20).
+		Execute()
+
+	for _, r := range results {
+		assert.False(t, r.Audit.CreatedAt.Before(from), "expect time to be within the range")
+		assert.True(t, r.Audit.CreatedAt.Before(to), "expect time to be within the range")
+		assert.False(t, r.Audit.UpdatedAt.Before(from), "expect *time.Time to be within the range")
+		assert.True(t, r.Audit.UpdatedAt.Before(to), "expect *time.Time to be within the range")
+	}
+}
+
+func test_time_location(t *testing.T) {
+	loc := time.FixedZone("AST", -4*60*60)
+
+	result := salem.For[auditRecord]().
+		WithTimeLocation(loc).
+		One()
+
+	assert.Equal(t, loc, result.CreatedAt.Location(), "expect time to use WithTimeLocation(...)")
+	assert.Equal(t, time.UTC, salem.For[auditRecord]().One().CreatedAt.Location(), "expect UTC by default")
+}
+
+func test_monotonic_time(t *testing.T) {
+	from := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	f := salem.For[order]().
+		WithTimeRange("Events.CreatedAt", from, from.Add(time.Hour)).
+		WithMonotonicTime("Events.CreatedAt", time.Minute).
+		Ensure("Events", salem.Tap().WithExactItems(5)).
+		WithExactItems(3)
+
+	results := f.Execute()
+
+	assert.Equal(t, from, results[0].Events[0].CreatedAt, "expect the first item to start at the range")
+
+	events := []auditRecord{}
+	for _, r := range results {
+		events = append(events, r.Events...)
+	}
+
+	for i := 1; i < len(events); i++ {
+		step := events[i].CreatedAt.Sub(events[i-1].CreatedAt)
+
+		assert.True(t, step > 0, "expect successive items to have increasing timestamps")
+		assert.True(t, step <= time.Minute, "expect steps to be within maxStep")
+	}
+
+	assert.Equal(t, from, f.Execute()[0].Events[0].CreatedAt, "expect each Execute to restart the timestamps")
+}
+
+func test_time_ensure(t *testing.T) {
+	createdAt := time.Date(2021, time.June, 23, 0, 0, 0, 0, time.UTC)
+
+	result := salem.For[auditRecord]().
+		Ensure("CreatedAt", createdAt).
+		Ensure("Timeout", time.Second).
+		One()
+
+	assert.Equal(t, createdAt, result.CreatedAt)
+	assert.Equal(t, time.Second, result.Timeout)
+}
+
+func test_time_validate(t *testing.T) {
+	f := salem.For[auditRecord]().
+		WithTimeRange("Timeout", time.Now(), time.Now()).
+		WithMonotonicTime("UpdatedAt", time.Second)
+
+	err := f.Validate().(*salem.ValidationError)
+	assert.Equal(t, 1, len(err.Errors), "expect time options on non time.Time fields to be reported")
+	assert.Equal(t, "Timeout", err.Errors[0].FieldName)
+}
+
+func test_time_array_elements(t *testing.T) {
+	from := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(1991, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	results := salem.For[shift]().
+		WithTimeRange("Times[0]", from, to).
+		WithMonotonicTime("Times[1]", time.Minute).
+		WithExactItems(10).
+		Execute()
+
+	for i, r := range results {
+		assert.False(t, r.Times[0].Before(from), "expect WithTimeRange(...) to apply to the array element")
+		assert.True(t, r.Times[0].Before(to), "expect WithTimeRange(...) to apply to the array element")
+
+		if i > 0 {
+			step := r.Times[1].Sub(results[i-1].Times[1])
+			assert.True(t, step > 0 && step <= time.Minute, "expect WithMonotonicTime(...) to apply to the array element")
+		}
+	}
+}
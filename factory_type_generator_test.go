@@ -0,0 +1,115 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type accountID [4]byte
+type currencyCode string
+
+type amount struct {
+	Units    int64
+	Currency currencyCode
+}
+
+type ledger struct {
+	ID       accountID
+	Balance  amount
+	Pending  *amount
+	Entries  []amount
+	Refunds  []*amount
+	Accounts map[accountID]amount
+	Codes    map[currencyCode]*currencyCode
+}
+
+var (
+	accountIDType    = reflect.TypeOf(accountID{})
+	amountType       = reflect.TypeOf(amount{})
+	currencyCodeType = reflect.TypeOf(currencyCode(""))
+
+	fixedAmount = amount{Units: 1250, Currency: "JMD"}
+)
+
+func newLedgerFactory() *salem.TypedFactory[ledger] {
+	var nextID byte
+
+	return salem.For[ledger]().
+		RegisterTypeGenerator(accountIDType, func() interface{} {
+			nextID++
+			return accountID{nextID, nextID, nextID, nextID}
+		}).
+		RegisterTypeGenerator(amountType, func() interface{} { return fixedAmount }).
+		RegisterTypeGenerator(currencyCodeType, func() interface{} { return currencyCode("USD") })
+}
+
+func Test_FactoryTypeGenerator(t *testing.T) {
+	test_type_generator_fields(t)
+	test_type_generator_nested(t)
+	test_type_generator_precedence(t)
+	test_package_type_generator(t)
+}
+
+func test_type_generator_fields(t *testing.T) {
+	result, err := newLedgerFactory().ExecuteE()
+
+	assert.Nil(t, err)
+	assert.Equal(t, accountID{1, 1, 1, 1}, result[0].ID, "expect registered array type to be generated")
+	assert.Equal(t, fixedAmount, result[0].Balance, "expect registered struct type to be generated")
+	assert.Equal(t, fixedAmount, *result[0].Pending, "expect pointer to registered type to be generated")
+}
+
+func test_type_generator_nested(t *testing.T) {
+	result := newLedgerFactory().
+		Ensure("Entries", salem.Tap().WithExactItems(3)).
+		WithExactMapItems("Accounts", 2).
+		One()
+
+	assert.Equal(t, []amount{fixedAmount, fixedAmount, fixedAmount}, result.Entries, "expect slice elements to be generated")
+	assert.Equal(t, fixedAmount, *result.Refunds[0], "expect pointer slice elements to be generated")
+
+	assert.Equal(t, 2, len(result.Accounts), "expect map keys to be generated")
+	for id, value := range result.Accounts {
+		assert.Equal(t, id[0], id[3], "expect map keys to be generated")
+		assert.Equal(t, fixedAmount, value, "expect map values to be generated")
+	}
+
+	for key, value := range result.Codes {
+		assert.Equal(t, currencyCode("USD"), key, "expect named primitive map keys to be generated")
+		assert.Equal(t, currencyCode("USD"), *value, "expect named primitive map values to be generated")
+	}
+}
+
+func test_type_generator_precedence(t *testing.T) {
+	balance := amount{Units: 1, Currency: "EUR"}
+
+	result := newLedgerFactory().
+		Ensure("Balance", balance).
+		One()
+
+	assert.Equal(t, balance, result.Balance, "expect Ensure(...) to take precedence")
+	assert.Equal(t, fixedAmount, *result.Pending)
+}
+
+func test_package_type_generator(t *testing.T) {
+	salem.RegisterTypeGenerator(amountType, func() interface{} { return amount{Units: 99, Currency: "GBP"} })
+	defer salem.UnregisterTypeGenerator(amountType)
+
+	type invoice struct {
+		Total amount
+	}
+
+	result := salem.For[invoice]().One()
+	assert.Equal(t, amount{Units: 99, Currency: "GBP"}, result.Total, "expect package level generators to be used")
+
+	result = salem.For[invoice]().
+		RegisterTypeGenerator(amountType, func() interface{} { return fixedAmount }).
+		One()
+	assert.Equal(t, fixedAmount, result.Total, "expect factory generators to take precedence")
+}
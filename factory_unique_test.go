@@ -0,0 +1,179 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type member struct {
+	ID       uint8
+	Email    string `salem:"oneof=a@x.com|b@x.com|c@x.com"`
+	Team     bool
+	Level    int8 `salem:"min=1,max=3"`
+	Settings map[bool]int
+	Friends  []friend
+}
+
+type friend struct {
+	Handle uint8
+}
+
+type referral struct {
+	Code   *string `salem:"oneof=a|b"`
+	Source source
+}
+
+type source struct {
+	Channel *string `salem:"oneof=web|app"`
+}
+
+func Test_FactoryUnique(t *testing.T) {
+	test_unique_field(t)
+	test_unique_composite(t)
+	test_unique_across_executions(t)
+	test_unique_tapped(t)
+	test_unique_exhausted(t)
+	test_unique_map_keys(t)
+	test_unique_pointers(t)
+	test_unique_element_paths(t)
+}
+
+func test_unique_field(t *testing.T) {
+	results := salem.For[member]().
+		EnsureUnique("ID").
+		EnsureUnique("Email").
+		WithExactMapItems("Settings", 1).
+		WithExactItems(3).
+		Execute()
+
+	emails := map[string]bool{}
+	ids := map[uint8]bool{}
+	for _, m := range results {
+		emails[m.Email] = true
+		ids[m.ID] = true
+	}
+
+	assert.Equal(t, 3, len(emails), "expect every email to be used once")
+	assert.Equal(t, 3, len(ids))
+}
+
+func test_unique_composite(t *testing.T) {
+	results := salem.For[member]().
+		EnsureUnique("Team", "Level").
+		WithExactMapItems("Settings", 1).
+		WithExactItems(6).
+		Execute()
+
+	combinations := map[[2]interface{}]bool{}
+	for _, m := range results {
+		combinations[[2]interface{}{m.Team, m.Level}] = true
+	}
+
+	assert.Equal(t, 6, len(combinations), "expect every combination of Team and Level to be used once")
+}
+
+func test_unique_across_executions(t *testing.T) {
+	f := salem.For[member]().
+		EnsureUniqueAcrossExecutions("Email").
+		WithExactMapItems("Settings", 1)
+
+	emails := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		emails[f.One().Email] = true
+	}
+	assert.Equal(t, 3, len(emails), "expect the emails to be unique across executions")
+
+	_, err := f.ExecuteE()
+	assert.NotNil(t, err, "expect an error once every email was used")
+
+	perExecution := salem.For[member]().EnsureUnique("Email").WithExactMapItems("Settings", 1)
+	for i := 0; i < 5; i++ {
+		_, err := perExecution.ExecuteE()
+		assert.Nil(t, err, "expect EnsureUnique(...) to reset for each execution")
+	}
+}
+
+func test_unique_tapped(t *testing.T) {
+	result := salem.For[member]().
+		WithExactMapItems("Settings", 1).
+		Ensure("Friends", salem.Tap().
+			WithExactItems(50).
+			EnsureUnique("Friends.Handle")).
+		One()
+
+	handles := map[uint8]bool{}
+	for _, f := range result.Friends {
+		handles[f.Handle] = true
+	}
+	assert.Equal(t, 50, len(handles), "expect the tapped items to be unique")
+}
+
+func test_unique_exhausted(t *testing.T) {
+	_, err := salem.For[member]().
+		EnsureUnique("Team").
+		WithExactMapItems("Settings", 1).
+		WithExactItems(3).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the values run out")
+	assert.Equal(t, 2, constraintErr.ItemIndex)
+	assert.Contains(t, err.Error(), "Unique(Team)")
+
+	err = salem.For[member]().EnsureUnique("Emial").Validate()
+	assert.NotNil(t, err, "expect unique paths to be validated")
+}
+
+func test_unique_map_keys(t *testing.T) {
+	results := salem.For[member]().
+		WithExactMapItems("Settings", 2).
+		WithExactItems(10).
+		Execute()
+
+	for _, m := range results {
+		assert.Equal(t, 2, len(m.Settings), "expect the map keys to be unique")
+	}
+
+	_, err := salem.For[member]().
+		WithExactMapItems("Settings", 3).
+		ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect a *ConstraintError when the keys run out")
+	assert.Equal(t, "Settings", constraintErr.FieldName)
+}
+
+func test_unique_pointers(t *testing.T) {
+	results := salem.For[referral]().EnsureUnique("Code").WithExactItems(2).Execute()
+	assert.NotEqual(t, *results[0].Code, *results[1].Code, "expect pointers to be unique by the values they point to")
+
+	_, err := salem.For[referral]().EnsureUnique("Code").WithExactItems(3).ExecuteE()
+
+	var constraintErr *salem.ConstraintError
+	assert.True(t, errors.As(err, &constraintErr), "expect equal values behind different pointers to be duplicates")
+
+	_, err = salem.For[referral]().EnsureUnique("Source").WithExactItems(3).ExecuteE()
+	assert.True(t, errors.As(err, &constraintErr), "expect the pointers held by structs to be compared by value")
+}
+
+func test_unique_element_paths(t *testing.T) {
+	f := salem.For[member]().EnsureUnique("Friends.Handle")
+	var pathErr *salem.FieldPathError
+
+	assert.True(t, errors.As(f.Validate(), &pathErr), "expect Validate() to reject a unique path that crosses a slice")
+	assert.Equal(t, "Friends.Handle", pathErr.FieldName)
+
+	_, err := f.ExecuteE()
+	assert.True(t, errors.As(err, &pathErr), "expect ExecuteE() to reject a unique path that crosses a slice")
+
+	tapped := salem.For[member]().
+		WithExactMapItems("Settings", 1).
+		Ensure("Friends", salem.Tap().EnsureUnique("Friends.Handle"))
+	assert.Nil(t, tapped.Validate(), "expect the path to be valid on the tapped factory")
+}
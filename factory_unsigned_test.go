@@ -0,0 +1,82 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type packetHeader struct {
+	Version  uint8
+	Flags    uint16
+	Length   uint32
+	Sequence uint64
+	Checksum uint
+	Address  uintptr
+}
+
+func Test_FactoryUnsigned(t *testing.T) {
+	test_unsigned_fields(t)
+	test_unsigned_pointers(t)
+	test_unsigned_maps(t)
+	test_unsigned_slices(t)
+}
+
+func test_unsigned_fields(t *testing.T) {
+	results, err := salem.For[packetHeader]().
+		WithExactItems(20).
+		ExecuteE()
+
+	assert.Nil(t, err, "expect unsigned fields to be mocked")
+	assert.Equal(t, 20, len(results))
+
+	var hasNonZero bool
+	for _, r := range results {
+		hasNonZero = hasNonZero || r.Length != 0 || r.Sequence != 0
+	}
+	assert.True(t, hasNonZero, "expect unsigned values to be generated")
+}
+
+func test_unsigned_pointers(t *testing.T) {
+	type packet struct {
+		Length   *uint32
+		Sequence *uint64
+		Header   *packetHeader
+	}
+
+	result := salem.For[packet]().One()
+
+	assert.NotNil(t, result.Length, "expect *uint32 to be mocked")
+	assert.NotNil(t, result.Sequence, "expect *uint64 to be mocked")
+	assert.NotNil(t, result.Header, "expect nested unsigned fields to be mocked")
+}
+
+func test_unsigned_maps(t *testing.T) {
+	type routingTable struct {
+		Ports   map[uint16]uint8
+		Offsets map[string]*uint32
+	}
+
+	result := salem.For[routingTable]().
+		WithExactMapItems("Ports", 5).
+		One()
+
+	assert.Equal(t, 5, len(result.Ports), "expect unsigned map keys and values")
+	assert.Equal(t, 1, len(result.Offsets), "expect unsigned pointer map values")
+}
+
+func test_unsigned_slices(t *testing.T) {
+	type frame struct {
+		Payload []uint32
+	}
+
+	result := salem.For[frame]().
+		Ensure("Payload", salem.Tap().WithExactItems(4)).
+		One()
+
+	assert.Equal(t, 4, len(result.Payload), "expect unsigned slice elements")
+}
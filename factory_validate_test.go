@@ -0,0 +1,102 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type engine struct {
+	Cylinders    int
+	SerialNumber string
+}
+
+type car struct {
+	TransactionGUID string
+	Engine          *engine
+	Owners          []person
+	Parts           map[string]engine
+	hidden          string
+}
+
+func Test_FactoryValidate(t *testing.T) {
+	test_validate_valid_paths(t)
+	test_validate_unknown_paths(t)
+	test_validate_wrong_kind(t)
+	test_validate_tapped_paths(t)
+}
+
+func test_validate_valid_paths(t *testing.T) {
+	f := salem.Mock(car{}).
+		Ensure("TransactionGUID", "GUID-153").
+		Omit("Engine.Cylinders").
+		EnsureConstraint("Engine.SerialNumber", salem.ConstrainStringLength(2, 5)).
+		Ensure("Owners", salem.Tap().EnsureSequence("Owners.Name", "Ted")).
+		EnsureMapKeySequence("Parts", "Intake").
+		WithExactMapItems("Parts", 1).
+		OnField("Parts.SerialNumber", func(int) interface{} { return "SN" })
+
+	assert.Nil(t, f.Validate(), "expect valid paths to pass validation")
+}
+
+func test_validate_unknown_paths(t *testing.T) {
+	f := salem.Mock(car{}).
+		Ensure("Car.TransactionGuid", "GUID-153").
+		Omit("Engine.Cylinder").
+		Ensure("hidden", "secret")
+
+	err := f.Validate()
+
+	var validationErr *salem.ValidationError
+	assert.True(t, errors.As(err, &validationErr), "expect a *ValidationError")
+	assert.Equal(t, 3, len(validationErr.Errors), "expect every invalid path to be reported")
+
+	assert.Equal(t, "Car.TransactionGuid", validationErr.Errors[0].FieldName)
+	assert.Equal(t, "TransactionGUID", validationErr.Errors[0].Suggestion, "expect the closest valid path")
+
+	assert.Equal(t, "Engine.Cylinder", validationErr.Errors[1].FieldName)
+	assert.Equal(t, "Engine.Cylinders", validationErr.Errors[1].Suggestion)
+
+	assert.Equal(t, "hidden", validationErr.Errors[2].FieldName, "expect private fields to be reported")
+
+	var pathErr *salem.FieldPathError
+	assert.True(t, errors.As(err, &pathErr), "expect errors.As(...) to find a *FieldPathError")
+}
+
+func test_validate_wrong_kind(t *testing.T) {
+	f := salem.Mock(car{}).
+		WithExactMapItems("Engine", 2).
+		Ensure("TransactionGUID", salem.Tap())
+
+	err := f.Validate()
+
+	var validationErr *salem.ValidationError
+	assert.True(t, errors.As(err, &validationErr), "expect a *ValidationError")
+	assert.Equal(t, 2, len(validationErr.Errors))
+	assert.Equal(t, "Engine", validationErr.Errors[0].FieldName, "expect map options on non-map fields to be reported")
+	assert.Contains(t, validationErr.Errors[0].Reason, "WithExactMapItems:", "expect the option that was used")
+	assert.Equal(t, "TransactionGUID", validationErr.Errors[1].FieldName, "expect Tap() on non-slice fields to be reported")
+
+	assert.Empty(t, validationErr.Errors[0].Suggestion, "expect no suggestion for paths that resolve")
+	assert.NotContains(t, validationErr.Errors[0].Error(), "Did you mean", "expect no suggestion for paths that resolve")
+}
+
+func test_validate_tapped_paths(t *testing.T) {
+	f := salem.Mock(car{}).
+		Ensure("Owners", salem.Tap().Ensure("Owners.Nmae", "Ted"))
+
+	err := f.Validate()
+
+	var pathErr *salem.FieldPathError
+	assert.True(t, errors.As(err, &pathErr), "expect paths on tapped factories to be validated")
+	assert.Equal(t, "Owners.Nmae", pathErr.FieldName)
+	assert.Equal(t, "Owners.Name", pathErr.Suggestion)
+
+	f.Execute() // The tapped plan now holds copies of the parent's paths
+	assert.Equal(t, 1, len(f.Validate().(*salem.ValidationError).Errors), "expect each invalid path to be reported once")
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"errors"
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type signup struct {
+	Email    string   `validate:"required,email,max=100"`
+	Username string   `validate:"required,alphanum,min=3,max=12"`
+	Name     string   `validate:"required,min=3,max=40"`
+	Colour   string   `validate:"oneof=red green blue"`
+	Age      uint8    `validate:"gte=18,lte=99"`
+	Score    int      `validate:"gt=0,lt=10"`
+	Active   bool     `validate:"required"`
+	Website  *string  `validate:"omitempty,url"`
+	Tags     []string `validate:"min=2,max=3"`
+	Code     string   `salem:"len=4" validate:"len=8"`
+	Size     string   `validate:"oneof=s m xl xxl,max=2"`
+	Rank     int      `validate:"oneof=1 5 20,gt=2"`
+}
+
+type unsupportedSignup struct {
+	Email   string   `validate:"email|url"`
+	Friends []string `validate:"min=2,max=2,dive,alpha"`
+	Joined  string   `validate:"datetime=2006-01-02"`
+	Count   int      `validate:"alpha"`
+	Contact string   `validate:"required,email,max=10"`
+}
+
+func Test_FactoryValidatorTags(t *testing.T) {
+	test_validator_tags_rules(t)
+	test_validator_tags_opt_in(t)
+	test_validator_tags_unsupported(t)
+}
+
+func test_validator_tags_rules(t *testing.T) {
+	results := salem.For[signup]().WithValidatorTags().WithExactItems(10).Execute()
+
+	for _, s := range results {
+		assert.Regexp(t, `^[a-z]+@[a-z]+\.com$`, s.Email, "expect email to generate emails")
+		assert.Regexp(t, `^[a-zA-Z0-9]{3,12}$`, s.Username, "expect alphanum to be combined with the length")
+		assert.True(t, len(s.Name) >= 3 && len(s.Name) <= 40, "expect min= and max= to limit the string length")
+		assert.Contains(t, []string{"red", "green", "blue"}, s.Colour, "expect oneof= to pick one of the options")
+		assert.True(t, s.Age >= 18 && s.Age <= 99, "expect gte= and lte= to limit numbers")
+		assert.True(t, s.Score > 0 && s.Score < 10, "expect gt= and lt= to limit integers")
+		assert.True(t, s.Active, "expect required bools to be true")
+		assert.Regexp(t, `^https://`, *s.Website, "expect url to generate URLs")
+		assert.True(t, len(s.Tags) >= 2 && len(s.Tags) <= 3, "expect min= and max= to limit the slice length")
+		assert.Equal(t, 4, len(s.Code), "expect the salem tag to take precedence")
+		assert.Contains(t, []string{"s", "m", "xl"}, s.Size, "expect max= to drop the longer oneof= values")
+		assert.Contains(t, []int{5, 20}, s.Rank, "expect gt= to drop the smaller oneof= values")
+	}
+}
+
+func test_validator_tags_opt_in(t *testing.T) {
+	s := salem.For[signup]().WithSeed(3).One()
+
+	assert.NotContains(t, s.Email, "@", "expect validate tags to be ignored by default")
+	assert.Nil(t, salem.For[unsupportedSignup]().Validate(), "expect validate tags to be ignored by default")
+}
+
+func test_validator_tags_unsupported(t *testing.T) {
+	f := salem.For[unsupportedSignup]().WithValidatorTags()
+
+	err := f.Validate()
+
+	var validationErr *salem.ValidationError
+	assert.True(t, errors.As(err, &validationErr), "expect a *ValidationError")
+	assert.Equal(t, 6, len(validationErr.Errors), "expect every unsupported rule to be reported")
+	assert.Equal(t, "Email", validationErr.Errors[0].FieldName)
+	assert.Contains(t, validationErr.Errors[0].Error(), "email|url")
+
+	assert.Equal(t, "Friends", validationErr.Errors[1].FieldName)
+	assert.Contains(t, validationErr.Errors[1].Error(), "'dive'")
+	assert.Equal(t, "Friends", validationErr.Errors[2].FieldName)
+	assert.Contains(t, validationErr.Errors[2].Error(), "'alpha'", "expect the rules after dive to be reported")
+
+	assert.Equal(t, "Contact", validationErr.Errors[5].FieldName)
+	assert.Contains(t, validationErr.Errors[5].Error(), "'max=10'", "expect bounds that the generator can't meet to be reported")
+
+	signups, err := f.ExecuteE()
+	assert.Nil(t, err, "expect unsupported rules to be skipped while generating")
+	assert.Equal(t, 2, len(signups[0].Friends), "expect the rules before dive to be applied")
+}
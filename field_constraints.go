@@ -3,6 +3,13 @@
 // license that can be found in the LICENSE file.
 package salem
 
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+)
+
 // SuggestedConstraintRetryAttempts is the default number of times to try generating a new mock before failing
 const SuggestedConstraintRetryAttempts = 40
 
@@ -10,17 +17,227 @@ type FieldConstraint interface {
 	IsValid(field interface{}) bool
 }
 
+// GenerativeConstraint is a FieldConstraint that generates valid values directly from the random source.
+//
+// The plan prefers Generate(...) to the field's generator. The generated value is still checked with
+// IsValid(...) and Generate(...) is retried when the value is invalid. The field's generator is used
+// for the attempts where Generate(...) returns false. The built-in constraints implement GenerativeConstraint.
+type GenerativeConstraint interface {
+	FieldConstraint
+
+	// Generate returns a valid value of fieldType. False is returned when fieldType can't hold a valid value
+	Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool)
+}
+
 type stringFieldConstraint struct {
 	min int
 	max int
 }
 
 func (s *stringFieldConstraint) IsValid(field interface{}) bool {
-	str := field.(string)
+	str := reflect.ValueOf(field).String() // Allows for named string types. E.g. type Status string
 
 	return len(str) >= s.min && len(str) <= s.max
 }
 
+func (s *stringFieldConstraint) Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	if fieldType.Kind() != reflect.String || s.max < 0 || s.min > s.max {
+		return nil, false
+	}
+
+	n := int(randUint64Range(rng, uint64(max(s.min, 0)), uint64(s.max)))
+
+	return reflect.ValueOf(randCharacters(rng, n)).Convert(fieldType).Interface(), true
+}
+
+func (s *stringFieldConstraint) String() string {
+	return fmt.Sprintf("StringLength[%v, %v]", s.min, s.max)
+}
+
 func ConstrainStringLength(min int, max int) FieldConstraint {
 	return &stringFieldConstraint{min: min, max: max}
 }
+
+type intRangeConstraint struct {
+	min int64
+	max int64
+}
+
+// ConstrainIntRange limits the field to [min, max].
+//
+// The constraint can be used on fields of any numeric kind, including named types, and the values are generated within the range,
+// so narrow ranges such as [10000, 20000] don't need any retries.
+func ConstrainIntRange(min int64, max int64) FieldConstraint {
+	return &intRangeConstraint{min: min, max: max}
+}
+
+func (c *intRangeConstraint) IsValid(field interface{}) bool {
+	val := reflect.ValueOf(field)
+
+	switch {
+	case val.CanInt():
+		return val.Int() >= c.min && val.Int() <= c.max
+
+	case val.CanUint():
+		return val.Uint() <= math.MaxInt64 && int64(val.Uint()) >= c.min && int64(val.Uint()) <= c.max
+
+	case val.CanFloat():
+		return val.Float() >= float64(c.min) && val.Float() <= float64(c.max)
+	}
+
+	return false
+}
+
+func (c *intRangeConstraint) Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	b := numericBounds{imin: c.min, imax: c.max, umin: 0, umax: 0, fmin: float64(c.min), fmax: float64(c.max)}
+	if c.max >= 0 {
+		b.umin, b.umax = uint64(max(c.min, 0)), uint64(c.max)
+	} else {
+		b.noUint = true
+	}
+
+	return b.generate(rng, fieldType)
+}
+
+func (c *intRangeConstraint) String() string {
+	return fmt.Sprintf("IntRange[%v, %v]", c.min, c.max)
+}
+
+type uintRangeConstraint struct {
+	min uint64
+	max uint64
+}
+
+// ConstrainUintRange limits the field to [min, max]. See ConstrainIntRange
+func ConstrainUintRange(min uint64, max uint64) FieldConstraint {
+	return &uintRangeConstraint{min: min, max: max}
+}
+
+func (c *uintRangeConstraint) IsValid(field interface{}) bool {
+	val := reflect.ValueOf(field)
+
+	switch {
+	case val.CanInt():
+		return val.Int() >= 0 && uint64(val.Int()) >= c.min && uint64(val.Int()) <= c.max
+
+	case val.CanUint():
+		return val.Uint() >= c.min && val.Uint() <= c.max
+
+	case val.CanFloat():
+		return val.Float() >= float64(c.min) && val.Float() <= float64(c.max)
+	}
+
+	return false
+}
+
+func (c *uintRangeConstraint) Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	b := numericBounds{umin: c.min, umax: c.max, fmin: float64(c.min), fmax: float64(c.max)}
+	if c.min <= math.MaxInt64 {
+		b.imin, b.imax = int64(c.min), int64(min(c.max, math.MaxInt64))
+	} else {
+		b.noInt = true
+	}
+
+	return b.generate(rng, fieldType)
+}
+
+func (c *uintRangeConstraint) String() string {
+	return fmt.Sprintf("UintRange[%v, %v]", c.min, c.max)
+}
+
+type floatRangeConstraint struct {
+	min float64
+	max float64
+}
+
+// ConstrainFloatRange limits the field to [min, max]. See ConstrainIntRange
+func ConstrainFloatRange(min float64, max float64) FieldConstraint {
+	return &floatRangeConstraint{min: min, max: max}
+}
+
+func (c *floatRangeConstraint) IsValid(field interface{}) bool {
+	val := reflect.ValueOf(field)
+
+	switch {
+	case val.CanInt():
+		return float64(val.Int()) >= c.min && float64(val.Int()) <= c.max
+
+	case val.CanUint():
+		return float64(val.Uint()) >= c.min && float64(val.Uint()) <= c.max
+
+	case val.CanFloat():
+		return val.Float() >= c.min && val.Float() <= c.max
+	}
+
+	return false
+}
+
+func (c *floatRangeConstraint) Generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	b := numericBounds{fmin: c.min, fmax: c.max}
+
+	// Integer fields get the whole numbers within the range
+	lo, hi := math.Ceil(c.min), math.Floor(c.max)
+	b.noInt = lo > hi || hi < math.MinInt64 || lo >= math.MaxInt64
+	if !b.noInt {
+		b.imin, b.imax = int64(math.Max(lo, math.MinInt64)), int64(math.Min(hi, math.Nextafter(math.MaxInt64, 0)))
+	}
+
+	b.noUint = lo > hi || hi < 0 || lo >= math.MaxUint64
+	if !b.noUint {
+		b.umin, b.umax = uint64(math.Max(lo, 0)), uint64(math.Min(hi, math.Nextafter(math.MaxUint64, 0)))
+	}
+
+	return b.generate(rng, fieldType)
+}
+
+func (c *floatRangeConstraint) String() string {
+	return fmt.Sprintf("FloatRange[%v, %v]", c.min, c.max)
+}
+
+// numericBounds holds a range for each family of numeric kinds.
+// noInt and noUint are set when the signed or unsigned kinds can't hold any of the range's values.
+type numericBounds struct {
+	imin, imax int64
+	umin, umax uint64
+	fmin, fmax float64
+
+	noInt  bool
+	noUint bool
+}
+
+// generate returns a value of the numeric fieldType within the bounds of its kind.
+// The bounds are narrowed to the values the kind can hold. False is returned when none of the values fit.
+func (b numericBounds) generate(rng *rand.Rand, fieldType reflect.Type) (interface{}, bool) {
+	val := reflect.New(fieldType).Elem()
+
+	switch {
+	case val.CanInt():
+		kindMin, kindMax := intBounds(fieldType)
+		lo, hi := max(b.imin, kindMin), min(b.imax, kindMax)
+		if b.noInt || lo > hi {
+			return nil, false
+		}
+
+		val.SetInt(int64(randUint64Range(rng, uint64(lo), uint64(hi))))
+
+	case val.CanUint():
+		hi := min(b.umax, math.MaxUint64>>(64-fieldType.Bits()))
+		if b.noUint || b.umin > hi {
+			return nil, false
+		}
+
+		val.SetUint(randUint64Range(rng, b.umin, hi))
+
+	case val.CanFloat():
+		if b.fmin > b.fmax || fieldType.Kind() == reflect.Float32 && (b.fmin < -math.MaxFloat32 || b.fmax > math.MaxFloat32) {
+			return nil, false
+		}
+
+		val.SetFloat(b.fmin + rng.Float64()*(b.fmax-b.fmin))
+
+	default:
+		return nil, false
+	}
+
+	return val.Interface(), true
+}
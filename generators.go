@@ -4,27 +4,37 @@
 package salem
 
 import (
-	"hmc/utils"
+	"fmt"
 	"math"
 	"math/rand"
 	"reflect"
+	"strings"
 )
 
 type GenType = func() interface{}
 
+const randCharacterSet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
 func (p *Plan) initDefaultGenerators() {
-	p.generators[reflect.Bool] = randBool
+	p.generators[reflect.Bool] = p.randBool
+
+	p.generators[reflect.Int] = p.randInt
+	p.generators[reflect.Int8] = p.randInt8
+	p.generators[reflect.Int16] = p.randInt16
+	p.generators[reflect.Int32] = p.randInt32
+	p.generators[reflect.Int64] = p.randInt64
 
-	p.generators[reflect.Int] = randInt
-	p.generators[reflect.Int8] = randInt8
-	p.generators[reflect.Int16] = randInt16
-	p.generators[reflect.Int32] = randInt32
-	p.generators[reflect.Int64] = randInt64
+	p.generators[reflect.Uint] = p.randUint
+	p.generators[reflect.Uint8] = p.randUint8
+	p.generators[reflect.Uint16] = p.randUint16
+	p.generators[reflect.Uint32] = p.randUint32
+	p.generators[reflect.Uint64] = p.randUint64
+	p.generators[reflect.Uintptr] = p.randUintptr
 
-	p.generators[reflect.Float32] = randFloat32
-	p.generators[reflect.Float64] = randFloat64
+	p.generators[reflect.Float32] = p.randFloat32
+	p.generators[reflect.Float64] = p.randFloat64
 
-	p.generators[reflect.String] = randString
+	p.generators[reflect.String] = p.randString
 
 	p.generators[reflect.Interface] = nilValue
 }
@@ -33,39 +43,127 @@ func (p *Plan) GetKindGenerator(k reflect.Kind) GenType {
 	return p.generators[k]
 }
 
-func randBool() interface{} {
-	if rand.Intn(1000000)%2 == 0 {
+func (p *Plan) randBool() interface{} {
+	if p.rng.Intn(1000000)%2 == 0 {
 		return true
 	}
 
 	return false
 }
-func randInt() interface{} {
-	return rand.Intn(math.MaxInt8)
+func (p *Plan) randInt() interface{} {
+	return p.rng.Intn(math.MaxInt8)
+}
+func (p *Plan) randInt8() interface{} {
+	return p.rng.Intn(math.MaxInt8)
+}
+func (p *Plan) randInt16() interface{} {
+	return p.rng.Int31n(math.MaxInt16)
+}
+func (p *Plan) randInt32() interface{} {
+	return p.rng.Int31n(math.MaxInt32)
 }
-func randInt8() interface{} {
-	return rand.Intn(math.MaxInt8)
+func (p *Plan) randInt64() interface{} {
+	return p.rng.Int63n(math.MaxInt64)
 }
-func randInt16() interface{} {
-	return rand.Int31n(math.MaxInt16)
+func (p *Plan) randUint() interface{} {
+	return uint(p.rng.Uint64()) // Truncated to 32 bits on 32-bit platforms
 }
-func randInt32() interface{} {
-	return rand.Int31n(math.MaxInt32)
+func (p *Plan) randUint8() interface{} {
+	return uint8(p.rng.Intn(math.MaxUint8 + 1))
 }
-func randInt64() interface{} {
-	return rand.Int63n(math.MinInt64)
+func (p *Plan) randUint16() interface{} {
+	return uint16(p.rng.Intn(math.MaxUint16 + 1))
 }
-func randFloat32() interface{} {
-	return rand.Float32()
+func (p *Plan) randUint32() interface{} {
+	return p.rng.Uint32()
 }
-func randFloat64() interface{} {
-	return rand.Float64()
+func (p *Plan) randUint64() interface{} {
+	return p.rng.Uint64()
 }
-func randString() interface{} {
-	len := rand.Intn(50)
-	return utils.RandCharacters(3 + len) // Ensure we always have at least 3 chars
+func (p *Plan) randUintptr() interface{} {
+	return uintptr(p.rng.Uint64()) // Truncated to 32 bits on 32-bit platforms
+}
+func (p *Plan) randFloat32() interface{} {
+	return p.rng.Float32()
+}
+func (p *Plan) randFloat64() interface{} {
+	return p.rng.Float64()
+}
+func (p *Plan) randString() interface{} {
+	len := p.rng.Intn(50)
+	return randCharacters(p.rng, 3+len) // Ensure we always have at least 3 chars
 }
 
 func nilValue() interface{} {
 	return nil
 }
+
+// randCharacters returns a string of n random characters taken from randCharacterSet
+func randCharacters(rng *rand.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = randCharacterSet[rng.Intn(len(randCharacterSet))]
+	}
+
+	return string(b)
+}
+
+// randInt64Range returns an int64 in [min, max]
+func (p *Plan) randInt64Range(min int64, max int64) int64 {
+	return int64(randUint64Range(p.rng, uint64(min), uint64(max))) // The unsigned offset wraps around for negative ranges
+}
+
+// randUint64Range returns a uint64 in [min, max]
+func (p *Plan) randUint64Range(min uint64, max uint64) uint64 {
+	return randUint64Range(p.rng, min, max)
+}
+
+// randUint64Range returns a uint64 in [min, max].
+// Signed ranges can be passed as uint64 since the offset from min wraps around.
+func randUint64Range(rng *rand.Rand, min uint64, max uint64) uint64 {
+	span := max - min + 1
+	switch {
+	case span == 0: // The range covers every uint64
+		return rng.Uint64()
+
+	case span <= math.MaxInt64:
+		return min + uint64(rng.Int63n(int64(span)))
+	}
+
+	return min + rng.Uint64()%span
+}
+
+// randFloat64Range returns a float64 in [min, max)
+func (p *Plan) randFloat64Range(min float64, max float64) float64 {
+	return min + p.rng.Float64()*(max-min)
+}
+
+// namedGenerators are the string generators that can be selected by name. E.g. `salem:"gen=email"`
+var namedGenerators = map[string]func(p *Plan) string{
+	"email": (*Plan).randEmail,
+	"url":   (*Plan).randURL,
+	"uuid":  (*Plan).randUUID,
+	"word":  (*Plan).randWord,
+}
+
+// randWord returns a lower case word of 3 to 10 letters
+func (p *Plan) randWord() string {
+	return strings.ToLower(randCharacters(p.rng, 3+p.rng.Intn(8)))
+}
+func (p *Plan) randEmail() string {
+	return fmt.Sprintf("%s@%s.com", p.randWord(), p.randWord())
+}
+func (p *Plan) randURL() string {
+	return fmt.Sprintf("https://%s.com/%s", p.randWord(), p.randWord())
+}
+
+// randUUID returns a random (version 4) UUID
+func (p *Plan) randUUID() string {
+	b := make([]byte, 16)
+	p.rng.Read(b)
+
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
@@ -0,0 +1,293 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GoLiteralOption configures WriteGoLiteral(...)
+type GoLiteralOption func(gw *goLiteralWriter)
+
+// GoLiteralPackage is the import path of the package the literal is written for.
+// The types of that package aren't qualified. E.g. GoLiteralPackage(reflect.TypeOf(Person{}).PkgPath())
+func GoLiteralPackage(pkgPath string) GoLiteralOption {
+	return func(gw *goLiteralWriter) {
+		gw.pkgPath = pkgPath
+	}
+}
+
+// goLiteralWriter formats values as Go composite literals
+type goLiteralWriter struct {
+	pkgPath   string
+	ptrHelper string // The name of the generic func used for pointers to values that can't use &. E.g. &"text"
+	usesPtr   bool
+}
+
+// writeGoLiteral writes the items as a Go variable declaration. See Factory.WriteGoLiteral(...)
+func (p *Plan) writeGoLiteral(w io.Writer, rootType reflect.Type, items []interface{}, varName string, opts []GoLiteralOption) error {
+	gw := &goLiteralWriter{ptrHelper: varName + "Ptr"}
+	for _, opt := range opts {
+		opt(gw)
+	}
+
+	slice := reflect.MakeSlice(reflect.SliceOf(rootType), len(items), len(items))
+	for i, item := range items {
+		val := reflect.ValueOf(item)
+		if rootType.Kind() == reflect.Ptr { // The plan returns values. E.g. for salem.Mock(&Person{})
+			val = toPtr(val)
+		}
+		slice.Index(i).Set(val)
+	}
+
+	literal, err := gw.literal(slice, false, false)
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "var %v = %v\n", varName, literal)
+
+	if gw.usesPtr {
+		fmt.Fprintf(&sb, "\n// %v returns a pointer to v\nfunc %v[T any](v T) *T { return &v }\n", gw.ptrHelper, gw.ptrHelper)
+	}
+
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return fmt.Errorf("can't format the Go literal: %w", err)
+	}
+
+	_, err = w.Write(src)
+
+	return err
+}
+
+// literal formats v. The type of composite literals is left out when elided is set. E.g. the elements of a slice.
+// Untyped constants are converted to v's type when typed is set. E.g. in interface{} fields
+func (gw *goLiteralWriter) literal(v reflect.Value, typed bool, elided bool) (string, error) {
+	t := v.Type()
+
+	switch {
+	case t == timeType:
+		return timeLiteral(v.Interface().(time.Time)), nil
+
+	case isPrimitiveKind(t):
+		lit := constantLiteral(v)
+		if typed {
+			return fmt.Sprintf("%v(%v)", gw.typeName(t), lit), nil
+		}
+		return lit, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "nil", nil
+		}
+
+		switch elem := t.Elem(); {
+		case elem != timeType && (elem.Kind() == reflect.Struct || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Map || elem.Kind() == reflect.Array):
+			lit, err := gw.literal(v.Elem(), false, elided)
+			if err != nil || elided {
+				return lit, err
+			}
+			return "&" + lit, nil
+
+		default:
+			lit, err := gw.literal(v.Elem(), false, false)
+			if err != nil {
+				return "", err
+			}
+
+			gw.usesPtr = true
+			return fmt.Sprintf("%v[%v](%v)", gw.ptrHelper, gw.typeName(elem), lit), nil
+		}
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		return gw.literal(v.Elem(), true, false)
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		return gw.elementsLiteral(v, elided)
+
+	case reflect.Array:
+		return gw.elementsLiteral(v, elided)
+
+	case reflect.Map:
+		if v.IsNil() {
+			return "nil", nil
+		}
+		return gw.mapLiteral(v, elided)
+
+	case reflect.Struct:
+		return gw.structLiteral(v, elided)
+	}
+
+	return "", fmt.Errorf("can't write %v values as Go literals", t)
+}
+
+// compositeType returns the type of a composite literal, or nothing when the type is elided
+func (gw *goLiteralWriter) compositeType(t reflect.Type, elided bool) string {
+	if elided {
+		return ""
+	}
+
+	return gw.typeName(t)
+}
+
+func (gw *goLiteralWriter) elementsLiteral(v reflect.Value, elided bool) (string, error) {
+	elems := make([]string, v.Len())
+	for i := range elems {
+		lit, err := gw.literal(v.Index(i), false, true)
+		if err != nil {
+			return "", err
+		}
+		elems[i] = lit
+	}
+
+	return gw.compositeType(v.Type(), elided) + "{\n" + joinLines(elems) + "}", nil
+}
+
+func (gw *goLiteralWriter) mapLiteral(v reflect.Value, elided bool) (string, error) {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+
+	entries := make([]string, len(keys))
+	for i, key := range keys {
+		k, err := gw.literal(key, false, true)
+		if err != nil {
+			return "", err
+		}
+		val, err := gw.literal(v.MapIndex(key), false, true)
+		if err != nil {
+			return "", err
+		}
+		entries[i] = k + ": " + val
+	}
+
+	return gw.compositeType(v.Type(), elided) + "{\n" + joinLines(entries) + "}", nil
+}
+
+// structLiteral sets the exported fields that don't have their zero value
+func (gw *goLiteralWriter) structLiteral(v reflect.Value, elided bool) (string, error) {
+	t := v.Type()
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || v.Field(i).IsZero() {
+			continue
+		}
+
+		lit, err := gw.literal(v.Field(i), field.Type.Kind() == reflect.Interface, false)
+		if err != nil {
+			return "", fmt.Errorf("%v.%v: %w", t, field.Name, err)
+		}
+		fields = append(fields, field.Name+": "+lit)
+	}
+
+	if len(fields) == 0 {
+		return gw.compositeType(t, elided) + "{}", nil
+	}
+
+	return gw.compositeType(t, elided) + "{\n" + joinLines(fields) + "}", nil
+}
+
+// typeName returns the Go syntax of t. The types of the package set by GoLiteralPackage(...) aren't qualified
+func (gw *goLiteralWriter) typeName(t reflect.Type) string {
+	if t.Name() != "" {
+		if t.PkgPath() == "" || t.PkgPath() == gw.pkgPath {
+			return t.Name()
+		}
+		return t.String() // E.g. examples.Person
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + gw.typeName(t.Elem())
+	case reflect.Slice:
+		return "[]" + gw.typeName(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%v", t.Len(), gw.typeName(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%v]%v", gw.typeName(t.Key()), gw.typeName(t.Elem()))
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+	}
+
+	return t.String() // E.g. anonymous structs
+}
+
+// constantLiteral formats bools, numbers and strings
+func constantLiteral(v reflect.Value) string {
+	switch {
+	case v.Kind() == reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10)
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10)
+	case v.CanFloat():
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			return "math.NaN()"
+		case math.IsInf(f, 1):
+			return "math.Inf(1)"
+		case math.IsInf(f, -1):
+			return "math.Inf(-1)"
+		}
+
+		lit := strconv.FormatFloat(f, 'g', -1, v.Type().Bits())
+		if !strings.ContainsAny(lit, ".eE") {
+			lit += ".0" // Keep the constant a float. E.g. in interface{} fields
+		}
+		return lit
+	}
+
+	return strconv.Quote(v.String())
+}
+
+// timeLiteral formats the time as a call to time.Date(...)
+func timeLiteral(tm time.Time) string {
+	var loc string
+	switch name, offset := tm.Zone(); {
+	case tm.Location() == time.UTC:
+		loc = "time.UTC"
+	case tm.Location() == time.Local:
+		loc = "time.Local"
+	default:
+		loc = fmt.Sprintf("time.FixedZone(%q, %d)", name, offset)
+	}
+
+	return fmt.Sprintf("time.Date(%d, time.%v, %d, %d, %d, %d, %d, %v)",
+		tm.Year(), tm.Month(), tm.Day(), tm.Hour(), tm.Minute(), tm.Second(), tm.Nanosecond(), loc)
+}
+
+// joinLines puts each part on its own line with a trailing comma
+func joinLines(parts []string) string {
+	var sb strings.Builder
+	for _, part := range parts {
+		sb.WriteString(part + ",\n")
+	}
+
+	return sb.String()
+}
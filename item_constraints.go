@@ -0,0 +1,115 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// derivedField is a field whose value is computed from the rest of the item
+type derivedField struct {
+	fieldName string
+	derive    func(item interface{}) interface{}
+}
+
+// AddItemConstraint adds a constraint that the whole item must meet.
+// Items that don't meet all of the constraints are generated again.
+func (p *Plan) AddItemConstraint(isValid func(item interface{}) bool) {
+	description := fmt.Sprintf("EnsureItem #%d", len(p.itemConstraints)+1)
+
+	p.itemConstraints = append(p.itemConstraints, Predicate(isValid, description))
+}
+
+// SetDerivedField computes the value of the field from the generated item.
+// The fields are derived in the order they were set, so a field can be derived from a derived field.
+func (p *Plan) SetDerivedField(fieldName string, derive func(item interface{}) interface{}) {
+	for i := range p.derivedFields {
+		if p.derivedFields[i].fieldName == fieldName {
+			p.derivedFields[i].derive = derive
+			return
+		}
+	}
+
+	p.derivedFields = append(p.derivedFields, derivedField{fieldName: fieldName, derive: derive})
+}
+
+// generateItem generates an item that meets the item constraints and has unique values for the unique fields.
+// The derived fields are set before the item is checked.
+func (p *Plan) generateItem(mockType reflect.Type, itemIndex int) interface{} {
+	if len(p.itemConstraints) == 0 && len(p.derivedFields) == 0 && len(p.uniqueFields) == 0 {
+		return p.generateRandomMock(mockType, itemIndex)
+	}
+
+	for attempt := 1; ; attempt++ { // Try till the item is valid or give up after maxConstraintRetryAttempts attemps
+		item := p.generateRandomMock(mockType, itemIndex)
+
+		for _, derived := range p.derivedFields {
+			// Tapped factories use the qualified name, e.g. Owners.Name, like the other options
+			fieldName := strings.TrimPrefix(derived.fieldName, p.parentName+".")
+			item = setFieldPath(item, fieldName, derived.derive(item), itemIndex)
+		}
+
+		failed := p.failedItemConstraint(item)
+		if failed == nil {
+			var keys []string
+			if failed, keys = p.duplicateUniqueFields(item); failed == nil {
+				p.recordUniqueFields(keys)
+				return item
+			}
+		}
+
+		if attempt > p.maxConstraintRetryAttempts {
+			panic(&ConstraintError{ItemIndex: itemIndex, Constraint: failed, Failed: failed, Attempts: attempt})
+		}
+	}
+}
+
+// failedItemConstraint returns the first item constraint that the item doesn't meet
+func (p *Plan) failedItemConstraint(item interface{}) FieldConstraint {
+	for _, constraint := range p.itemConstraints {
+		if !constraint.IsValid(item) {
+			return constraint
+		}
+	}
+
+	return nil
+}
+
+// setFieldPath returns a copy of the item with the field set to value.
+// A nil value sets the field to its zero value. E.g. a nil pointer.
+func setFieldPath(item interface{}, fieldName string, value interface{}, itemIndex int) interface{} {
+	copied := reflect.New(reflect.TypeOf(item)).Elem()
+	copied.Set(reflect.ValueOf(item))
+
+	field := copied
+	for _, name := range strings.Split(fieldName, ".") {
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+
+		if field.Kind() != reflect.Struct {
+			panic(&FieldPathError{FieldName: fieldName, Reason: fmt.Sprintf("Can't derive the field since '%v' is in a %v", name, field.Kind())})
+		}
+
+		field = field.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			panic(&FieldPathError{FieldName: fieldName, Reason: fmt.Sprintf("Can't derive the field since '%v' is not an exported field", name)})
+		}
+	}
+
+	val := convertValue(reflect.ValueOf(value), field.Type())
+	switch {
+	case !val.IsValid():
+		val = reflect.Zero(field.Type())
+
+	case !val.Type().AssignableTo(field.Type()):
+		panic(&UnsupportedKindError{FieldName: fieldName, ItemIndex: itemIndex, Type: field.Type(), Reason: fmt.Sprintf("The derived %v can't be assigned to the field", val.Type())})
+	}
+
+	field.Set(val)
+
+	return copied.Interface()
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// JSONOptions configures WriteJSON(...). The zero value writes compact JSON.
+type JSONOptions struct {
+	// Prefix starts each line of indented JSON
+	Prefix string
+	// Indent is the indentation of each level. E.g. "  ". The JSON is indented when Prefix or Indent is set
+	Indent string
+}
+
+// writeJSON writes the items to w as a JSON array. See Factory.WriteJSON(...)
+func (p *Plan) writeJSON(f *Factory, w io.Writer, opts JSONOptions) error {
+	indented := opts.Prefix != "" || opts.Indent != ""
+
+	itemPrefix, end := "", "]\n"
+	if indented {
+		itemPrefix, end = "\n"+opts.Prefix+opts.Indent, "\n"+opts.Prefix+"]\n"
+	}
+
+	count := 0
+	err := p.each(f, func(item interface{}) error {
+		b, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		var buf bytes.Buffer
+		if count == 0 {
+			buf.WriteString("[")
+		} else {
+			buf.WriteString(",")
+		}
+		buf.WriteString(itemPrefix)
+
+		if indented {
+			if err := json.Indent(&buf, b, opts.Prefix+opts.Indent, opts.Indent); err != nil {
+				return err
+			}
+		} else {
+			buf.Write(b)
+		}
+
+		count++
+		_, err = w.Write(buf.Bytes())
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		end = "[]\n"
+	}
+	_, err = io.WriteString(w, end)
+
+	return err
+}
+
+// writeJSONLines writes each item to w as a line of compact JSON. See Factory.WriteJSONLines(...)
+func (p *Plan) writeJSONLines(f *Factory, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+
+	return p.each(f, func(item interface{}) error {
+		return encoder.Encode(item)
+	})
+}
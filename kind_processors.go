@@ -19,36 +19,66 @@ func (p *Plan) initKindProcessors() {
 	p.kindProcessors[reflect.Slice] = onSlice(p)
 	p.kindProcessors[reflect.Struct] = onStruct(p)
 	p.kindProcessors[reflect.Interface] = onInterface(p)
+	p.kindProcessors[reflect.Array] = onArray(p)
 }
 
 func onMap(p *Plan) processorType {
 	return func(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
+		if generator != nil && p.hasTypeGenerator(fieldType) {
+			val := generator()
+			return reflect.ValueOf(val)
+		}
+
 		newMap := reflect.MakeMap(fieldType)
 		mapKeyType := fieldType.Key()
 
 		fieldSequenceKeyAction := p.ensuredMapFields[qualifiedName].fieldSequenceKeyAction
-		if fieldSequenceKeyAction == nil && !isPrimitiveKind(mapKeyType) {
-			// Can't be generate the field by fieldSequenceAction(...) or p.GetKindGenerator(...)
-			panic(fmt.Sprintf("Don't know how to make the key-generator. Field: %v", qualifiedName))
+		keyPattern := p.ensuredMapFields[qualifiedName].keyPattern
+		keyChoice := p.ensuredMapFields[qualifiedName].keyChoice
+		if fieldSequenceKeyAction == nil && !isPrimitiveKind(mapKeyType) && p.typeProcessors[mapKeyType] == nil && !p.hasTypeGenerator(mapKeyType) {
+			// Can't be generate the field by fieldSequenceAction(...), p.GetKindGenerator(...), a type processor or a type generator
+			panic(&UnsupportedKindError{FieldName: qualifiedName, ItemIndex: itemIndex, Type: mapKeyType, Reason: "Don't know how to make the key-generator"})
 		}
 
 		var mapItemCount = 1
 		if p.evalMapItemCountAction[qualifiedName] != nil {
 			p.evalMapItemCountAction[qualifiedName]()
 			mapItemCount = p.mapPlanRun[qualifiedName].Count
+		} else if rule := p.tagRuleFor(qualifiedName); rule != nil && rule.hasItems {
+			mapItemCount = rule.itemCount(p)
+		}
+
+		// Dynamically create the keyGenerator. The field's tag only applies to the values
+		keyTypeGenerator := p.typeGenerator(mapKeyType, itemIndex, qualifiedName)
+		if keyChoice != nil {
+			if !keyChoice.fits(mapKeyType) {
+				panic(&FieldPathError{FieldName: qualifiedName, Reason: fmt.Sprintf("The values of %v(...) don't fit the %v keys", p.ensuredMapFields[qualifiedName].keyChoiceOption, mapKeyType)})
+			}
+			keyTypeGenerator = p.choiceGenerator(keyChoice)
+		} else if keyPattern != nil && mapKeyType.Kind() == reflect.String {
+			keyTypeGenerator = p.patternGenerator(keyPattern)
 		}
 
-		// Dynamically create the keyGenerator
-		keyGenerator := createMapKeyGenerator(p.GetKindGenerator(mapKeyType.Kind()), fieldSequenceKeyAction)
+		keyGenerator := createMapKeyGenerator(func() interface{} {
+			return p.generateFieldValue(keyTypeGenerator, mapKeyType, itemIndex, qualifiedName).Interface()
+		}, fieldSequenceKeyAction)
 
 		// Dynamically create the valueGenerator
-		valueGenerator := p.createMapValueGenerator(fieldType.Elem(), qualifiedName)
+		valueGenerator := p.createMapValueGenerator(fieldType.Elem(), itemIndex, qualifiedName)
 
 		for mapItemIndex := 0; mapItemIndex < mapItemCount; mapItemIndex++ {
-			key := keyGenerator(mapItemIndex)
-			val := valueGenerator(mapItemIndex)
+			key := convertValue(reflect.ValueOf(keyGenerator(mapItemIndex)), mapKeyType)
+
+			// Generated keys are unique so that the map gets the requested number of items
+			for attempt := 1; fieldSequenceKeyAction == nil && newMap.MapIndex(key).IsValid(); attempt++ {
+				if attempt > p.maxConstraintRetryAttempts {
+					panic(&ConstraintError{FieldName: qualifiedName, ItemIndex: itemIndex, Constraint: &uniqueMapKeys{count: mapItemCount}, Attempts: attempt})
+				}
+				key = convertValue(reflect.ValueOf(keyGenerator(mapItemIndex)), mapKeyType)
+			}
 
-			newMap.SetMapIndex(reflect.ValueOf(key), val)
+			val := valueGenerator(mapItemIndex)
+			newMap.SetMapIndex(key, convertValue(val, fieldType.Elem()))
 		}
 
 		return newMap
@@ -58,25 +88,34 @@ func onMap(p *Plan) processorType {
 func onSlice(p *Plan) processorType {
 	return func(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
 		if generator == nil {
-			factoryAction := makeFactoryAction(Tap(), p)
+			tap := Tap()
+			if rule := p.tagRuleFor(qualifiedName); rule != nil && rule.hasItems {
+				tap.WithExactItems(rule.itemCount(p))
+			}
+
+			factoryAction := makeFactoryAction(tap, p)
 			generator = factoryAction(fieldType, qualifiedName)
 		}
 
 		factorySlice := generator()
+		if reflect.TypeOf(factorySlice) == fieldType { // E.g. from a type generator or Ensure(...) with the same slice type
+			return reflect.ValueOf(factorySlice)
+		}
+
 		unboxedSlized := reflect.ValueOf(factorySlice)
 		num := unboxedSlized.Len()
 		newSlice := reflect.MakeSlice(fieldType, num, num)
 
 		if fieldType.Elem().Kind() == reflect.Ptr {
 			for i := 0; i < unboxedSlized.Len(); i++ {
-				obj := unboxedSlized.Index(i).Elem()
+				obj := convertValue(unboxedSlized.Index(i).Elem(), fieldType.Elem().Elem())
 				newSlice.Index(i).Set(toPtr(obj))
 			}
 			return newSlice
 		}
 
 		for i := 0; i < unboxedSlized.Len(); i++ {
-			newSlice.Index(i).Set(unboxedSlized.Index(i).Elem())
+			newSlice.Index(i).Set(convertValue(unboxedSlized.Index(i).Elem(), fieldType.Elem()))
 		}
 
 		return newSlice
@@ -106,6 +145,10 @@ func onStruct(p *Plan) processorType {
 
 func onPtr(p *Plan) processorType {
 	return func(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
+		if generator != nil && p.hasTypeGenerator(fieldType) { // E.g. a generator registered for *Money
+			val := generator()
+			return reflect.ValueOf(val)
+		}
 
 		ptrType := fieldType.Elem() // The pointer's type
 		generator = p.getValueGenerator(ptrType, itemIndex, qualifiedName)
@@ -114,6 +157,7 @@ func onPtr(p *Plan) processorType {
 		newElm := newMockPtr.Elem()
 
 		val := p.generateFieldValue(generator, newElm.Type(), itemIndex, qualifiedName)
+		val = convertValue(val, ptrType)
 
 		vp := reflect.New(val.Type())
 		vp.Elem().Set(reflect.ValueOf(val.Interface()))
@@ -122,6 +166,40 @@ func onPtr(p *Plan) processorType {
 	}
 }
 
+func onArray(p *Plan) processorType {
+	return func(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
+		if generator != nil { // The whole array was set. E.g. Ensure("Hash", [4]byte{...})
+			val := generator()
+			return reflect.ValueOf(val)
+		}
+
+		newArray := reflect.New(fieldType).Elem()
+		elemType := fieldType.Elem()
+
+		for i := 0; i < fieldType.Len(); i++ {
+			elemName := arrayElementName(qualifiedName, i)
+			if p.omittedFields[elemName] == true {
+				continue // Skip omitted elements
+			}
+
+			var val reflect.Value
+			if p.isFieldConfigured(elemName) || elemType.Kind() == reflect.Array { // E.g. Ensure("Hash[0]", byte(0xff)) or Matrix[1][0]
+				val = p.generateValue(elemType, itemIndex, elemName)
+			} else {
+				// Nested fields of the elements share the array's name. E.g. Points.X
+				val = p.generateFieldValue(p.defaultGenerator(elemType, itemIndex, qualifiedName), elemType, itemIndex, qualifiedName)
+			}
+
+			if !val.IsValid() {
+				continue
+			}
+			newArray.Index(i).Set(val)
+		}
+
+		return newArray
+	}
+}
+
 func onInterface(p *Plan) processorType {
 	return func(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
 		val := generator()
@@ -137,41 +215,50 @@ func createMapKeyGenerator(generator func() interface{}, fieldSequenceKeyAction
 			return fieldSequenceKeyAction(index)()
 		}
 	}
-	// If we get here we are guaranteed that the generator is a
-	// isPrimitiveKind(...) and comes from p.GetKindGenerator(...)
+	// If we get here we are guaranteed that the key is a isPrimitiveKind(...)
+	// or is generated by a type processor or type generator
 	return func(_ int) interface{} {
 		return generator()
 	}
 }
 
 // createMapValueGenerator is used to dynamically create the valueGenerator for a map's value
-func (p *Plan) createMapValueGenerator(mapValueType reflect.Type, qualifiedName string) func(param int) reflect.Value {
+func (p *Plan) createMapValueGenerator(mapValueType reflect.Type, itemIndex int, qualifiedName string) func(param int) reflect.Value {
 	fieldSequenceValueAction := p.ensuredMapFields[qualifiedName].fieldSequenceValueAction
+	valuePattern := p.ensuredMapFields[qualifiedName].valuePattern
 
 	if fieldSequenceValueAction != nil {
 		return func(index int) reflect.Value {
 			result := fieldSequenceValueAction(index)()
 			return reflect.ValueOf(result)
 		}
-	} else if isPrimitiveKind(mapValueType) {
+	} else if valuePattern != nil && tagValueType(mapValueType).Kind() == reflect.String {
+		generator := p.patternGenerator(valuePattern)
+
+		return func(_ int) reflect.Value {
+			return p.generateFieldValue(generator, mapValueType, itemIndex, qualifiedName)
+		}
+	} else if generator := p.defaultGenerator(mapValueType, itemIndex, qualifiedName); generator != nil && isPrimitiveKind(mapValueType) {
 		return func(_ int) reflect.Value {
-			result := p.GetKindGenerator(mapValueType.Kind())()
+			result := generator()
 			return reflect.ValueOf(result)
 		}
-	} else if isPrtPrimitiveKind(mapValueType) {
+	} else if isPrtPrimitiveKind(mapValueType) && p.defaultGenerator(mapValueType.Elem(), itemIndex, qualifiedName) != nil {
+		generator := p.defaultGenerator(mapValueType.Elem(), itemIndex, qualifiedName)
+
 		return func(_ int) reflect.Value {
 			// Get the primitive type the pointer points to then generate the primitive value
-			result := p.GetKindGenerator(mapValueType.Elem().Kind())()
+			result := generator()
 
 			// Convert value to a pointer
 			vp := reflect.New(mapValueType.Elem())
-			vp.Elem().Set(reflect.ValueOf(result))
+			vp.Elem().Set(convertValue(reflect.ValueOf(result), mapValueType.Elem()))
 
 			return vp // Return the pointer
 		}
 	}
 
 	return func(_ int) reflect.Value {
-		return p.generateFieldValue(nil, mapValueType, 0, qualifiedName)
+		return p.generateFieldValue(p.defaultGenerator(mapValueType, itemIndex, qualifiedName), mapValueType, itemIndex, qualifiedName)
 	}
 }
@@ -0,0 +1,86 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"math/rand"
+	"reflect"
+)
+
+// Mocker is implemented by types that generate their own mocks.
+//
+// The plan calls SalemMock(...) instead of mocking the type's fields or using the kind generator.
+// The method can have a value or pointer receiver and is called on a zero value of the type.
+// It can return either the type or a pointer to the type.
+type Mocker interface {
+	SalemMock(ctx GenContext) interface{}
+}
+
+// GenContext is passed to Mocker.SalemMock(...)
+type GenContext struct {
+	Rand      *rand.Rand // The factory's random source. Use it so that seeded runs generate the same mocks
+	ItemIndex int        // The index of the item being generated
+	FieldName string     // The qualified name of the field being generated. Empty for the items of the factory
+}
+
+var mockerType = reflect.TypeOf((*Mocker)(nil)).Elem()
+
+// implementsMocker returns true when t or *t implements Mocker
+func implementsMocker(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false // There is no value to call SalemMock(...) on
+	}
+
+	return t.Implements(mockerType) || reflect.PtrTo(t).Implements(mockerType)
+}
+
+// hasTypeGenerator returns true when the values of type t come from a type generator or a Mocker
+func (p *Plan) hasTypeGenerator(t reflect.Type) bool {
+	return p.GetTypeGenerator(t) != nil || implementsMocker(t)
+}
+
+// mockerGenerator returns a generator that calls SalemMock(...) when t or *t implements Mocker.
+// The generated value is adjusted to be of type t.
+func (p *Plan) mockerGenerator(t reflect.Type, itemIndex int, qualifiedName string) GenType {
+	if !implementsMocker(t) {
+		return nil
+	}
+
+	var mocker Mocker
+	switch {
+	case t.Kind() == reflect.Ptr && t.Implements(mockerType):
+		mocker = reflect.New(t.Elem()).Interface().(Mocker)
+
+	case t.Implements(mockerType):
+		mocker = reflect.Zero(t).Interface().(Mocker)
+
+	default:
+		mocker = reflect.New(t).Interface().(Mocker)
+	}
+
+	return func() interface{} {
+		ctx := GenContext{Rand: p.rng, ItemIndex: itemIndex, FieldName: qualifiedName}
+		val := reflect.ValueOf(mocker.SalemMock(ctx))
+
+		switch {
+		case !val.IsValid() || val.Type() == t:
+			// Nothing to adjust
+
+		case val.Kind() == reflect.Ptr && val.Type().Elem() == t:
+			if val.IsNil() {
+				return nil
+			}
+			val = val.Elem()
+
+		case t.Kind() == reflect.Ptr && val.Type() == t.Elem():
+			val = toPtr(val)
+		}
+
+		if !val.IsValid() {
+			return nil
+		}
+
+		return val.Interface()
+	}
+}
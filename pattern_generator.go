@@ -0,0 +1,160 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"errors"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxPatternRepeat is the upper bounds used for the unbounded repetitions *, + and {n,}
+const maxPatternRepeat = 10
+
+// printableASCII is the range of characters preferred for ., negated classes and other wide classes
+var printableASCII = []rune{' ', '~'}
+
+// patternGenerator generates strings that match a regular expression
+type patternGenerator struct {
+	re *syntax.Regexp
+}
+
+// newPatternGenerator parses the regular expression using the regexp/syntax (Perl) flags.
+//
+// The expression isn't simplified since Simplify() turns x{1,6} into nested optional groups,
+// which would make the shorter repetitions much more likely than the longer ones.
+func newPatternGenerator(pattern string) (*patternGenerator, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasEmptyClass(re) {
+		return nil, errors.New("a character class matches no characters")
+	}
+
+	return &patternGenerator{re: re}, nil
+}
+
+// hasEmptyClass reports whether re has a character class that no rune is in. E.g. [^\x00-\x{10FFFF}]
+func hasEmptyClass(re *syntax.Regexp) bool {
+	if re.Op == syntax.OpCharClass && len(re.Rune) == 0 {
+		return true
+	}
+
+	for _, sub := range re.Sub {
+		if hasEmptyClass(sub) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *patternGenerator) generate(rng *rand.Rand) string {
+	var sb strings.Builder
+	writePattern(&sb, g.re, rng)
+
+	return sb.String()
+}
+
+// writePattern writes a random string that matches re
+func writePattern(sb *strings.Builder, re *syntax.Regexp, rng *rand.Rand) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			sb.WriteRune(r)
+		}
+
+	case syntax.OpCharClass:
+		sb.WriteRune(randRuneInClass(re.Rune, rng))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(randRuneInClass(printableASCII, rng))
+
+	case syntax.OpCapture:
+		writePattern(sb, re.Sub[0], rng)
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writePattern(sb, sub, rng)
+		}
+
+	case syntax.OpAlternate:
+		writePattern(sb, re.Sub[rng.Intn(len(re.Sub))], rng)
+
+	case syntax.OpStar:
+		writeRepeat(sb, re.Sub[0], 0, -1, rng)
+
+	case syntax.OpPlus:
+		writeRepeat(sb, re.Sub[0], 1, -1, rng)
+
+	case syntax.OpQuest:
+		writeRepeat(sb, re.Sub[0], 0, 1, rng)
+
+	case syntax.OpRepeat:
+		writeRepeat(sb, re.Sub[0], re.Min, re.Max, rng)
+
+	default:
+		// Empty matches, anchors and word boundaries don't add any characters
+	}
+}
+
+// writeRepeat writes re [min, max] times. A max of -1 means there is no upper bounds.
+func writeRepeat(sb *strings.Builder, re *syntax.Regexp, min int, max int, rng *rand.Rand) {
+	if max < 0 {
+		max = min + maxPatternRepeat
+	}
+
+	n := min + rng.Intn(max-min+1)
+	for i := 0; i < n; i++ {
+		writePattern(sb, re, rng)
+	}
+}
+
+// randRuneInClass picks a rune from the [lo, hi] pairs of a character class.
+// Printable ASCII characters are preferred so that classes such as [^a-z] give readable strings.
+func randRuneInClass(class []rune, rng *rand.Rand) rune {
+	if printable := intersectClass(class, printableASCII); len(printable) > 0 {
+		class = printable
+	}
+
+	var total int
+	for i := 0; i < len(class); i += 2 {
+		total += int(class[i+1]-class[i]) + 1
+	}
+
+	n := rng.Intn(total)
+	for i := 0; i < len(class); i += 2 {
+		size := int(class[i+1]-class[i]) + 1
+		if n < size {
+			return class[i] + rune(n)
+		}
+		n -= size
+	}
+
+	return class[0]
+}
+
+// intersectClass returns the parts of the class ranges that are within [bounds[0], bounds[1]]
+func intersectClass(class []rune, bounds []rune) []rune {
+	var result []rune
+
+	for i := 0; i < len(class); i += 2 {
+		lo, hi := class[i], class[i+1]
+		if lo < bounds[0] {
+			lo = bounds[0]
+		}
+		if hi > bounds[1] {
+			hi = bounds[1]
+		}
+
+		if lo <= hi {
+			result = append(result, lo, hi)
+		}
+	}
+
+	return result
+}
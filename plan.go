@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"math/rand"
 	"reflect"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -21,6 +23,7 @@ const (
 	MinRun
 	MaxRun
 	ExactRun
+	UnboundedRun // Generates items until the consumer stops. See Factory.WithUnboundedItems()
 )
 
 type PlanRun struct {
@@ -32,12 +35,20 @@ type fieldSetter struct {
 	fieldAction         GenType
 	factoryAction       FactoryActionType
 	fieldSequenceAction SequenceActionType
+
+	tappedFactory *Factory // the factory behind factoryAction
 }
 
 // mapSetter used to hold the generators for keys of values for a map field
 type mapSetter struct {
 	fieldSequenceKeyAction   SequenceActionType
 	fieldSequenceValueAction SequenceActionType
+
+	keyPattern   *patternGenerator // set via EnsureMapKeyPattern
+	valuePattern *patternGenerator // set via EnsureMapValuePattern
+	keyChoice    *valueChoice      // set via EnsureMapKeyOneOf or EnsureMapKeyWeighted
+
+	keyChoiceOption string // the option that set keyChoice. Used to report the values that don't fit
 }
 
 type Plan struct {
@@ -55,12 +66,53 @@ type Plan struct {
 
 	mapPlanRun             map[string]*PlanRun // plan runs for different Maps
 	evalMapItemCountAction map[string]func()
+	mapItemCountOptions    map[string]string // The option that set each evalMapItemCountAction. E.g. WithExactMapItems
 
 	fieldHandlers map[string]fieldHandlerType // FieldName -> Handler
 
 	parentName string
 
 	maxConstraintRetryAttempts int
+
+	seed        int64      // seed used to create rng
+	hasSeed     bool       // true when the seed was set with SetSeed(...)
+	rng         *rand.Rand // random source shared by all of the generators
+	isNestedRun bool       // true when the plan shares the rng of a parent plan
+	state       *runState  // state shared by the plans of a run
+
+	typeProcessors map[reflect.Type]processorType
+	typeGenerators map[reflect.Type]GenType // generators set via RegisterTypeGenerator
+
+	timeRanges     map[string]timeRange     // fields set via WithTimeRange
+	monotonicTimes map[string]time.Duration // fields set via WithMonotonicTime -> max step between items
+	timeLocation   *time.Location
+
+	tagRules         map[string]*tagRule // rules read from the salem struct tags. Nil for fields without tags
+	useValidatorTags bool                // read the validate struct tags of fields without salem tags
+
+	itemConstraints []FieldConstraint // constraints on the whole item set via EnsureItem
+	derivedFields   []derivedField    // fields set via EnsureDerived
+	uniqueFields    []*uniqueFields   // fields set via EnsureUnique
+
+	patternFields map[string]*patternGenerator  // fields set via EnsurePattern
+	choiceFields  map[string]*valueChoice       // fields set via EnsureOneOf and EnsureWeighted
+	enums         map[reflect.Type]*valueChoice // types set via RegisterEnum
+
+	distributionFields map[string]Distribution       // fields set via EnsureDistribution
+	distributions      map[reflect.Type]Distribution // types set via RegisterDistribution
+
+	configErrors []*FieldPathError // options that couldn't be set. Reported by Validate() and when the plan runs
+}
+
+// runState holds the values that change as the items of a run are generated
+type runState struct {
+	lastTimes map[string]time.Time // the last time.Time generated for fields set via WithMonotonicTime
+}
+
+func newRunState() *runState {
+	return &runState{
+		lastTimes: make(map[string]time.Time),
+	}
 }
 
 func NewPlan() *Plan {
@@ -75,11 +127,26 @@ func NewPlan() *Plan {
 	p.ensuredMapFields = make(map[string]mapSetter)
 	p.mapPlanRun = make(map[string]*PlanRun)
 	p.evalMapItemCountAction = make(map[string]func())
+	p.mapItemCountOptions = make(map[string]string)
 
 	p.fieldHandlers = make(map[string]fieldHandlerType)
 
+	p.typeGenerators = make(map[reflect.Type]GenType)
+	p.timeRanges = make(map[string]timeRange)
+	p.monotonicTimes = make(map[string]time.Duration)
+	p.timeLocation = time.UTC
+	p.tagRules = make(map[string]*tagRule)
+	p.patternFields = make(map[string]*patternGenerator)
+	p.choiceFields = make(map[string]*valueChoice)
+	p.enums = make(map[reflect.Type]*valueChoice)
+	p.distributionFields = make(map[string]Distribution)
+	p.distributions = make(map[reflect.Type]Distribution)
+
+	// The random source and run state are created by each(...), or shared by the parent plan via CopyParentConstraints(...).
+	// Nested structs create a plan per value, so seeding a source here would be wasted work.
 	p.initDefaultGenerators()
 	p.initKindProcessors()
+	p.initTypeProcessors()
 
 	return p
 }
@@ -90,6 +157,43 @@ func (p *Plan) GetMapPlanRun(fieldName string) *PlanRun {
 	return p.mapPlanRun[fieldName]
 }
 
+// SetTimeRange sets the range of the time.Time values generated for the field
+func (p *Plan) SetTimeRange(fieldName string, from time.Time, to time.Time) {
+	p.timeRanges[fieldName] = timeRange{from: from, to: to}
+}
+
+// SetTimeLocation sets the location of the generated time.Time values
+func (p *Plan) SetTimeLocation(loc *time.Location) {
+	p.timeLocation = loc
+}
+
+// SetMonotonicTime generates increasing time.Time values for the field.
+// Each item is up to maxStep after the previous item.
+func (p *Plan) SetMonotonicTime(fieldName string, maxStep time.Duration) {
+	p.monotonicTimes[fieldName] = maxStep
+}
+
+// SetSeed fixes the seed used for the random source so that each run generates the same mocks
+func (p *Plan) SetSeed(seed int64) {
+	p.seed = seed
+	p.hasSeed = true
+}
+
+// GetSeed returns the seed used by the most recent run.
+// When no seed was set with SetSeed(...) this is the seed picked for that run.
+func (p *Plan) GetSeed() int64 {
+	return p.seed
+}
+
+// GetRand returns the random source used by the generators
+func (p *Plan) GetRand() *rand.Rand {
+	if p.rng == nil {
+		p.resetRandSource() // Not run yet
+	}
+
+	return p.rng
+}
+
 func (p *Plan) SetMaxConstraintsRetryAttempts(maxRetries int) {
 	p.maxConstraintRetryAttempts = maxRetries
 }
@@ -98,7 +202,13 @@ func (p *Plan) SetItemCountHandler(handler func()) {
 	p.evalItemCountAction = handler
 }
 func (p *Plan) SetMapItemCountHandler(fieldName string, handler func()) {
+	p.setMapItemCountHandler("SetMapItemCountHandler", fieldName, handler)
+}
+
+// setMapItemCountHandler records the option so that Validate() can report it
+func (p *Plan) setMapItemCountHandler(option string, fieldName string, handler func()) {
 	p.evalMapItemCountAction[fieldName] = handler
+	p.mapItemCountOptions[fieldName] = option
 }
 func (p *Plan) OmitField(fieldName string) {
 	p.omittedFields[fieldName] = true
@@ -115,13 +225,20 @@ func (p *Plan) EnsuredFieldValue(fieldName string, sharedValue interface{}) {
 	p.ensuredFields[fieldName] = setter
 }
 
+// EnsuredFieldValueConstraint adds a constraint to the field.
+// The constraints of a field stack, so the values have to meet all of them.
 func (p *Plan) EnsuredFieldValueConstraint(fieldName string, constraint FieldConstraint) {
+	if existing := p.constrainedFields[fieldName]; existing != nil {
+		constraint = And(existing, constraint)
+	}
+
 	p.constrainedFields[fieldName] = constraint
 }
 
 func (p *Plan) EnsuredFactoryFieldValue(fieldName string, sharedValue interface{}) {
 	setter := fieldSetter{
 		factoryAction: makeFactoryAction(sharedValue.(*Factory), p),
+		tappedFactory: sharedValue.(*Factory),
 	}
 
 	p.ensuredFields[fieldName] = setter
@@ -157,6 +274,58 @@ func (p *Plan) EnsureSequenceAcross(fieldName string, seq []interface{}) {
 	p.ensuredFields[fieldName] = fieldSetter{fieldSequenceAction: seqHandler}
 }
 
+// addConfigError records an option that couldn't be set. It is reported by Validate() and when the plan runs
+func (p *Plan) addConfigError(fieldName string, option string, reason string) {
+	p.configErrors = append(p.configErrors, &FieldPathError{FieldName: fieldName, Reason: fmt.Sprintf("%v: %v", option, reason)})
+}
+
+// EnsurePattern generates the field's strings from the regular expression.
+// A pattern that can't generate strings is reported by Validate() and when the plan runs
+func (p *Plan) EnsurePattern(fieldName string, pattern *regexp.Regexp) {
+	if g := p.fieldPatternGenerator(fieldName, "EnsurePattern", pattern); g != nil {
+		p.patternFields[fieldName] = g
+	}
+}
+
+// EnsureMapKeyPattern generates the map's keys from the regular expression. See EnsurePattern(...)
+func (p *Plan) EnsureMapKeyPattern(fieldName string, pattern *regexp.Regexp) {
+	if g := p.fieldPatternGenerator(fieldName, "EnsureMapKeyPattern", pattern); g != nil {
+		setter := p.ensuredMapFields[fieldName]
+		setter.keyPattern = g
+
+		p.ensuredMapFields[fieldName] = setter
+	}
+}
+
+// EnsureMapValuePattern generates the map's values from the regular expression. See EnsurePattern(...)
+func (p *Plan) EnsureMapValuePattern(fieldName string, pattern *regexp.Regexp) {
+	if g := p.fieldPatternGenerator(fieldName, "EnsureMapValuePattern", pattern); g != nil {
+		setter := p.ensuredMapFields[fieldName]
+		setter.valuePattern = g
+
+		p.ensuredMapFields[fieldName] = setter
+	}
+}
+
+// fieldPatternGenerator creates the generator for a compiled regular expression.
+// It returns nil, and records the config error, when the expression can't be used to generate strings
+func (p *Plan) fieldPatternGenerator(fieldName string, option string, pattern *regexp.Regexp) *patternGenerator {
+	g, err := newPatternGenerator(pattern.String())
+	if err != nil {
+		p.addConfigError(fieldName, option, fmt.Sprintf("Can't generate strings for the pattern '%v': %v", pattern, err))
+		return nil
+	}
+
+	return g
+}
+
+// patternGenerator returns a generator for the strings that match the pattern
+func (p *Plan) patternGenerator(pattern *patternGenerator) GenType {
+	return func() interface{} {
+		return pattern.generate(p.rng)
+	}
+}
+
 func (p *Plan) EnsureMapKeySequence(fieldName string, seq []interface{}) {
 	var setter mapSetter
 	if _, ok := p.ensuredMapFields[fieldName]; ok {
@@ -234,7 +403,7 @@ func (p *Plan) SetMapRunCount(fieldName string, runType RunType, n int) {
 
 func (p *Plan) AddFieldHandler(fieldName string, handler fieldHandlerType) {
 	if p.fieldHandlers[fieldName] != nil {
-		panic(fmt.Sprintf("There is already a fieldHandler assigned to to the `%v` field. Remove the fieldhandler first.", fieldName))
+		panic(&FieldPathError{FieldName: fieldName, Reason: "There is already a fieldHandler assigned to the field. Remove the fieldhandler first"})
 	}
 
 	p.fieldHandlers[fieldName] = handler
@@ -252,31 +421,133 @@ func (p *Plan) CopyParentConstraints(pp *Plan) {
 	for k, v := range pp.omittedFields {
 		p.omittedFields[k] = v
 	}
+
+	for k, v := range pp.constrainedFields {
+		p.constrainedFields[k] = v
+	}
+	p.maxConstraintRetryAttempts = pp.maxConstraintRetryAttempts
+
+	for k, v := range pp.typeGenerators {
+		p.typeGenerators[k] = v
+	}
+
+	for k, v := range pp.timeRanges {
+		p.timeRanges[k] = v
+	}
+
+	for k, v := range pp.monotonicTimes {
+		p.monotonicTimes[k] = v
+	}
+	p.timeLocation = pp.timeLocation
+
+	for k, v := range pp.tagRules {
+		p.tagRules[k] = v
+	}
+	p.useValidatorTags = pp.useValidatorTags
+
+	for k, v := range pp.patternFields {
+		p.patternFields[k] = v
+	}
+
+	for k, v := range pp.choiceFields {
+		p.choiceFields[k] = v
+	}
+
+	for k, v := range pp.enums {
+		p.enums[k] = v
+	}
+
+	for k, v := range pp.distributionFields {
+		p.distributionFields[k] = v
+	}
+
+	for k, v := range pp.distributions {
+		p.distributions[k] = v
+	}
+
+	// Nested plans draw from the parent's random source so that a seed
+	// reproduces the whole tree of mocks.
+	p.rng = pp.rng
+	p.seed = pp.seed
+	p.state = pp.state
+	p.isNestedRun = true
 }
 
-func (p *Plan) Run(f *Factory) []interface{} {
-	rand.Seed(time.Now().UnixNano())
-	p.evalItemCountAction()
+// resetRandSource creates a new random source from the plan's seed.
+// A new seed is picked when one was not set with SetSeed(...).
+func (p *Plan) resetRandSource() {
+	if !p.hasSeed {
+		p.seed = time.Now().UnixNano()
+	}
 
-	items := make([]interface{}, 0, p.run.Count)
+	p.rng = rand.New(rand.NewSource(p.seed))
+}
 
-	for itemIndex := 0; itemIndex < p.run.Count; itemIndex++ {
-		mockType := reflect.TypeOf(f.rootType)
+func (p *Plan) Run(f *Factory) []interface{} {
+	items := []interface{}{}
 
-		items = append(items, p.generateRandomMock(mockType, itemIndex))
+	p.startRun()
+	if p.run.RunType == UnboundedRun {
+		// Checked before the first item so that nothing is generated
+		panic(&UnboundedError{Reason: "Can't collect the items of an unbounded factory. Use Stream(...), All(), WriteJSON(...) or WriteJSONLines(...)"})
 	}
 
+	p.eachItem(f, func(item interface{}) error {
+		items = append(items, item)
+		return nil
+	})
+
 	return items
 }
 
-func (p *Plan) generateRandomMock(mockType reflect.Type, itemIndex int) interface{} {
-	rand.Seed(time.Now().UnixNano())
+// each generates the items one at a time and passes them to fn.
+// It stops at the first error returned by fn so that the items can be streamed. E.g. WriteJSON(...)
+func (p *Plan) each(f *Factory, fn func(item interface{}) error) error {
+	p.startRun()
+
+	return p.eachItem(f, fn)
+}
 
+// startRun resets the state of the previous run and evaluates the number of items
+func (p *Plan) startRun() {
+	if !p.isNestedRun {
+		p.resetRandSource()
+		p.state = newRunState()
+	}
+	if len(p.configErrors) > 0 {
+		panic(p.configErrors[0])
+	}
+
+	p.evalItemCountAction()
+	p.resetUniqueFields()
+}
+
+// eachItem generates the items of the run started by startRun() and passes them to fn. See each(...)
+func (p *Plan) eachItem(f *Factory, fn func(item interface{}) error) error {
+	mockType := reflect.TypeOf(f.rootType)
+
+	for itemIndex := 0; p.run.RunType == UnboundedRun || itemIndex < p.run.Count; itemIndex++ {
+		if err := fn(p.generateItem(mockType, itemIndex)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Plan) generateRandomMock(mockType reflect.Type, itemIndex int) interface{} {
 	// Create an mock instance of the struct
 	newMockPtr := reflect.New(mockType)
 	newElm := newMockPtr.Elem()
 
 	if mockType.Kind() == reflect.Ptr {
+		if generator := p.defaultGenerator(mockType, itemIndex, p.parentName); generator != nil {
+			// The plan returns values, so the generated pointer is dereferenced
+			if val := reflect.ValueOf(generator()); val.IsValid() && !val.IsNil() {
+				return val.Elem().Interface()
+			}
+		}
+
 		ptrType := mockType.Elem()
 
 		newMockPtr = reflect.New(ptrType) // Make an instance based on the pointer type
@@ -285,14 +556,19 @@ func (p *Plan) generateRandomMock(mockType reflect.Type, itemIndex int) interfac
 		mockType = newElm.Type()
 	}
 
-	if isPrimitiveKind(mockType) {
-		generator := p.GetKindGenerator(mockType.Kind())
-		val := generator()
-		return val
+	generator := p.defaultGenerator(mockType, itemIndex, p.parentName)
+	if mockType.Kind() != reflect.Struct || p.typeProcessors[mockType] != nil || generator != nil {
+		// E.g. the elements of []int, [][3]float64, []time.Time or []Money fields
+		val := p.generateFieldValue(generator, mockType, itemIndex, p.parentName)
+		if !val.IsValid() {
+			return nil
+		}
+		return val.Interface()
 	}
 
 	for i := 0; i < mockType.NumField(); i++ {
-		fieldName := mockType.Field(i).Name
+		field := mockType.Field(i)
+		fieldName := field.Name
 
 		iField := newElm.Field(i) // Get related instance field in the mock instance
 		if !iField.CanSet() {
@@ -304,6 +580,13 @@ func (p *Plan) generateRandomMock(mockType reflect.Type, itemIndex int) interfac
 			continue // Skip omitted fields
 		}
 
+		// The factory options set on the field take precedence over the field's tag
+		if rule := p.fieldTagRule(field, qualifiedName); rule != nil && !p.hasFieldOption(qualifiedName) {
+			if rule.omit || p.isLeftNil(rule) {
+				continue
+			}
+		}
+
 		val := p.generateValue(iField.Type(), itemIndex, qualifiedName)
 
 		if !val.IsValid() {
@@ -318,7 +601,7 @@ func (p *Plan) generateValue(fieldType reflect.Type, itemIndex int, qualifiedNam
 	if p.fieldHandlers[qualifiedName] != nil {
 		generator := p.fieldHandlers[qualifiedName]
 		val := generator(itemIndex)
-		return reflect.ValueOf(val)
+		return convertValue(reflect.ValueOf(val), fieldType)
 	}
 
 	generator := p.getValueGenerator(fieldType, itemIndex, qualifiedName)
@@ -332,15 +615,22 @@ func (p *Plan) generateValue(fieldType reflect.Type, itemIndex int, qualifiedNam
 	if isValueFromEnsureAction { // Ensure Ensured field meets constraint
 		val := p.generateFieldValue(generator, fieldType, itemIndex, qualifiedName)
 		if !constraint.IsValid(val.Interface()) {
-			panic(fmt.Sprintf("Constraint clashes with one of your Ensure methods. Invalid FieldConstraint for field '%v'. Constraint: %#v.", qualifiedName, constraint))
+			panic(&ConstraintError{FieldName: qualifiedName, ItemIndex: itemIndex, Constraint: constraint, Failed: failedConstraint(constraint, val.Interface())})
 		}
 		return val
 	}
 
+	generate := func() reflect.Value {
+		return p.generateFieldValue(generator, fieldType, itemIndex, qualifiedName)
+	}
+	if gc, ok := constraint.(GenerativeConstraint); ok && !p.hasFieldOption(qualifiedName) {
+		generate = p.constraintGenerator(gc, fieldType, generate)
+	}
+
 	var attempt = 0
 	var val reflect.Value
 	for { // Try till constraint is met or give up after maxConstraintRetryAttempts attemps
-		val = p.generateFieldValue(generator, fieldType, itemIndex, qualifiedName)
+		val = generate()
 		attempt += 1
 
 		if constraint.IsValid(val.Interface()) {
@@ -348,13 +638,27 @@ func (p *Plan) generateValue(fieldType reflect.Type, itemIndex int, qualifiedNam
 		}
 
 		if attempt > p.maxConstraintRetryAttempts {
-			panic(fmt.Sprintf("Unable to meet constraint %v after '%v' tries", constraint, p.maxConstraintRetryAttempts))
+			panic(&ConstraintError{FieldName: qualifiedName, ItemIndex: itemIndex, Constraint: constraint, Failed: failedConstraint(constraint, val.Interface()), Attempts: attempt})
 		}
 	}
 
 	return val
 }
 
+// constraintGenerator generates the field's values with the constraint's Generate(...).
+// The fallback is used when the constraint can't generate a value of fieldType.
+func (p *Plan) constraintGenerator(gc GenerativeConstraint, fieldType reflect.Type, fallback func() reflect.Value) func() reflect.Value {
+	return func() reflect.Value {
+		if generated, ok := gc.Generate(p.rng, fieldType); ok {
+			if val := convertValue(reflect.ValueOf(generated), fieldType); val.IsValid() && val.Type() == fieldType {
+				return val
+			}
+		}
+
+		return fallback()
+	}
+}
+
 func (p *Plan) getValueGenerator(fieldType reflect.Type, itemIndex int, qualifiedName string) GenType {
 	if p.ensuredFields[qualifiedName].factoryAction != nil {
 		return p.ensuredFields[qualifiedName].factoryAction(fieldType, qualifiedName)
@@ -367,26 +671,118 @@ func (p *Plan) getValueGenerator(fieldType reflect.Type, itemIndex int, qualifie
 
 	}
 
+	return p.defaultGenerator(fieldType, itemIndex, qualifiedName)
+}
+
+// defaultGenerator returns the generator for fields that weren't set with any of the Ensure options.
+//
+// The precedence is EnsureOneOf(...) and EnsureWeighted(...), EnsurePattern(...), EnsureDistribution(...),
+// the field's salem tag, then the generator for the field's type (see typeGenerator).
+func (p *Plan) defaultGenerator(fieldType reflect.Type, itemIndex int, qualifiedName string) GenType {
+	if choice := p.choiceFields[qualifiedName]; choice != nil && fieldType.Kind() != reflect.Map {
+		if choice.fits(fieldType) {
+			return p.choiceGenerator(choice)
+		}
+
+		// The values can still fit the elements of pointers, slices and arrays. E.g. EnsureOneOf("Tags", "a", "b") on []string
+		if k := fieldType.Kind(); k != reflect.Ptr && k != reflect.Slice && k != reflect.Array {
+			panic(&FieldPathError{FieldName: qualifiedName, Reason: fmt.Sprintf("The values of EnsureOneOf(...) or EnsureWeighted(...) don't fit the %v field", fieldType)})
+		}
+	}
+
+	if pattern := p.patternFields[qualifiedName]; pattern != nil && fieldType.Kind() == reflect.String {
+		return p.patternGenerator(pattern)
+	}
+
+	if dist := p.distributionFields[qualifiedName]; dist != nil && isNumericType(fieldType) {
+		return p.distributionGenerator(dist, fieldType)
+	}
+
+	if generator := p.tagGenerator(fieldType, qualifiedName); generator != nil {
+		return generator
+	}
+
+	return p.typeGenerator(fieldType, itemIndex, qualifiedName)
+}
+
+// typeGenerator returns the generator for values of fieldType.
+//
+// The precedence is type generators, Mocker types, type processors and then kind generators.
+// A nil generator is returned for types that are generated by a type processor.
+func (p *Plan) typeGenerator(fieldType reflect.Type, itemIndex int, qualifiedName string) GenType {
+	if err := p.enumError(fieldType); err != nil {
+		panic(&UnsupportedKindError{FieldName: qualifiedName, ItemIndex: itemIndex, Type: fieldType, Reason: err.Error()})
+	}
+
+	if generator := p.GetTypeGenerator(fieldType); generator != nil {
+		return generator
+	}
+
+	if generator := p.mockerGenerator(fieldType, itemIndex, qualifiedName); generator != nil {
+		return generator
+	}
+
+	if p.typeProcessors[fieldType] != nil {
+		return nil
+	}
+
 	return p.GetKindGenerator(fieldType.Kind())
 }
 
 func (p *Plan) generateFieldValue(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
+	if processor := p.typeProcessors[fieldType]; processor != nil {
+		return processor(generator, fieldType, itemIndex, qualifiedName)
+	}
+
 	if isPrimitiveKind(fieldType) {
+		if generator == nil {
+			panic(&UnsupportedKindError{FieldName: qualifiedName, ItemIndex: itemIndex, Type: fieldType, Reason: "There is no generator for the kind"})
+		}
+
 		val := generator()
-		return reflect.ValueOf(val)
+		return convertValue(reflect.ValueOf(val), fieldType)
 	}
 
 	k := fieldType.Kind()
 	processor := p.kindProcessors[k]
 
 	if processor == nil {
-		fmt.Printf("[updateFieldValue] (Unknow type) %v \n", fieldType.Name())
-		panic(fmt.Sprintf("[updateFieldValue] Unsupported type: %#v kind:%v", fieldType, k))
+		panic(&UnsupportedKindError{FieldName: qualifiedName, ItemIndex: itemIndex, Type: fieldType, Reason: "Unsupported type"})
 	}
 
 	return processor(generator, fieldType, itemIndex, qualifiedName)
 }
 
+// convertValue converts a generated primitive value to the declared type of the field.
+// E.g. a string to a `type Status string` field or an int to an int8 field.
+//
+// Values are only converted within the same family of kinds (bools, numbers or strings).
+// Other values, such as an int for a string field, are returned unchanged.
+func convertValue(val reflect.Value, fieldType reflect.Type) reflect.Value {
+	if !val.IsValid() || val.Type() == fieldType {
+		return val
+	}
+
+	if !isPrimitiveKind(val.Type()) || !isPrimitiveKind(fieldType) || kindFamily(val.Kind()) != kindFamily(fieldType.Kind()) {
+		return val
+	}
+
+	return val.Convert(fieldType)
+}
+
+// kindFamily groups the primitive kinds that can be converted to each other
+func kindFamily(k reflect.Kind) reflect.Kind {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+
+		return reflect.Float64
+	}
+
+	return k
+}
+
 // toPtr converts obj to *obj
 func toPtr(obj reflect.Value) reflect.Value {
 	vp := reflect.New(reflect.TypeOf(obj.Interface()))
@@ -413,6 +809,77 @@ func makeFactoryAction(fac *Factory, currentPlan *Plan) FactoryActionType {
 	}
 }
 
+// isFieldConfigured returns true when any of the factory options were set for the field or its nested fields
+func (p *Plan) isFieldConfigured(qualifiedName string) bool {
+	prefix := qualifiedName + "."
+	matches := func(name string) bool {
+		return name == qualifiedName || strings.HasPrefix(name, prefix)
+	}
+
+	for name := range p.ensuredFields {
+		if matches(name) {
+			return true
+		}
+	}
+	for name, constraint := range p.constrainedFields {
+		if constraint != nil && matches(name) {
+			return true
+		}
+	}
+	for name, handler := range p.fieldHandlers {
+		if handler != nil && matches(name) {
+			return true
+		}
+	}
+	for name, isOmitted := range p.omittedFields {
+		if isOmitted && matches(name) {
+			return true
+		}
+	}
+	for name := range p.ensuredMapFields {
+		if matches(name) {
+			return true
+		}
+	}
+	for name, handler := range p.evalMapItemCountAction {
+		if handler != nil && matches(name) {
+			return true
+		}
+	}
+	for name := range p.distributionFields {
+		if matches(name) {
+			return true
+		}
+	}
+	for name := range p.choiceFields {
+		if matches(name) {
+			return true
+		}
+	}
+	for name := range p.patternFields {
+		if matches(name) {
+			return true
+		}
+	}
+	for name := range p.timeRanges {
+		if matches(name) {
+			return true
+		}
+	}
+	for name := range p.monotonicTimes {
+		if matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// arrayElementName returns the name used to configure an element of an array field. E.g. Hash[0]
+func arrayElementName(qualifiedName string, index int) string {
+	return fmt.Sprintf("%s[%d]", qualifiedName, index)
+}
+
 func distinctFileName(parentFieldName string, fieldName string) string {
 	if parentFieldName == "" {
 		return fieldName
@@ -18,7 +18,7 @@ func Test_Plan(t *testing.T) {
 func test_default_generators(t *testing.T) {
 	p := NewPlan()
 
-	assert.Equal(t, 10, len(p.generators), "expect all default generators to created")
+	assert.Equal(t, 16, len(p.generators), "expect all default generators to created")
 
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.Bool), "expect generator for reflect.Bool")
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.Int), "expect generator for reflect.Int")
@@ -26,6 +26,12 @@ func test_default_generators(t *testing.T) {
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.Int16), "expect generator for reflect.Int16")
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.Int32), "expect generator for reflect.Int32")
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.Int64), "expect generator for reflect.Int64")
+	assert.NotEmpty(t, p.GetKindGenerator(reflect.Uint), "expect generator for reflect.Uint")
+	assert.NotEmpty(t, p.GetKindGenerator(reflect.Uint8), "expect generator for reflect.Uint8")
+	assert.NotEmpty(t, p.GetKindGenerator(reflect.Uint16), "expect generator for reflect.Uint16")
+	assert.NotEmpty(t, p.GetKindGenerator(reflect.Uint32), "expect generator for reflect.Uint32")
+	assert.NotEmpty(t, p.GetKindGenerator(reflect.Uint64), "expect generator for reflect.Uint64")
+	assert.NotEmpty(t, p.GetKindGenerator(reflect.Uintptr), "expect generator for reflect.Uintptr")
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.Float32), "expect generator for reflect.Float32")
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.Float64), "expect generator for reflect.Float64")
 	assert.NotEmpty(t, p.GetKindGenerator(reflect.String), "expect generator for reflect.String")
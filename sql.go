@@ -0,0 +1,344 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultSQLBatchSize is the number of rows in each INSERT statement written by WriteSQL(...)
+const DefaultSQLBatchSize = 100
+
+// sqlTimeLayout is the layout of timestamps. The times are converted to UTC
+const sqlTimeLayout = "2006-01-02 15:04:05.999999"
+
+// SQLDialect selects the quoting used by WriteSQL(...)
+type SQLDialect int
+
+const (
+	Postgres SQLDialect = iota
+	MySQL
+	SQLite
+)
+
+func (d SQLDialect) String() string {
+	switch d {
+	case Postgres:
+		return "Postgres"
+	case MySQL:
+		return "MySQL"
+	case SQLite:
+		return "SQLite"
+	}
+
+	return fmt.Sprintf("SQLDialect(%d)", int(d))
+}
+
+// SQLOption configures WriteSQL(...)
+type SQLOption func(opts *sqlOptions)
+
+type sqlOptions struct {
+	batchSize  int
+	nestedJSON bool
+}
+
+// SQLBatchSize sets the number of rows in each INSERT statement. See DefaultSQLBatchSize
+func SQLBatchSize(n int) SQLOption {
+	return func(opts *sqlOptions) {
+		opts.batchSize = n
+	}
+}
+
+// SQLNestedJSON writes nested structs as JSON columns instead of flattening their fields into columns
+func SQLNestedJSON() SQLOption {
+	return func(opts *sqlOptions) {
+		opts.nestedJSON = true
+	}
+}
+
+// sqlColumn is a column of the table and how to get its value from an item
+type sqlColumn struct {
+	name  string
+	value func(item reflect.Value) reflect.Value // Returns an invalid value for NULL. E.g. a nil pointer
+}
+
+// writeSQL writes the items to w as INSERT statements. See Factory.WriteSQL(...)
+func (p *Plan) writeSQL(f *Factory, w io.Writer, table string, dialect SQLDialect, opts []SQLOption) error {
+	if dialect != Postgres && dialect != MySQL && dialect != SQLite {
+		return fmt.Errorf("unsupported SQL dialect %v", dialect)
+	}
+
+	options := sqlOptions{batchSize: DefaultSQLBatchSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.batchSize <= 0 {
+		options.batchSize = DefaultSQLBatchSize
+	}
+
+	rootType := reflect.TypeOf(f.rootType)
+	for rootType.Kind() == reflect.Ptr {
+		rootType = rootType.Elem()
+	}
+	if rootType.Kind() != reflect.Struct {
+		return fmt.Errorf("can't write %v values as SQL rows", rootType)
+	}
+
+	columns := p.sqlColumns(rootType, "", "", func(v reflect.Value) reflect.Value { return v }, options, 0)
+
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = dialect.quoteIdentifier(column.name)
+	}
+	insert := fmt.Sprintf("INSERT INTO %v (%v) VALUES\n", dialect.quoteTable(table), strings.Join(names, ", "))
+
+	var sb strings.Builder
+	rows := 0
+
+	flush := func() error {
+		if rows == 0 {
+			return nil
+		}
+
+		sb.WriteString(";\n")
+		_, err := io.WriteString(w, sb.String())
+
+		sb.Reset()
+		rows = 0
+
+		return err
+	}
+
+	err := p.each(f, func(item interface{}) error {
+		if rows == 0 {
+			sb.WriteString(insert)
+		} else {
+			sb.WriteString(",\n")
+		}
+
+		values := make([]string, len(columns))
+		for i, column := range columns {
+			literal, err := dialect.literal(column.value(reflect.ValueOf(item)))
+			if err != nil {
+				return fmt.Errorf("can't write the SQL column '%v': %w", column.name, err)
+			}
+			values[i] = literal
+		}
+
+		sb.WriteString("(" + strings.Join(values, ", ") + ")")
+		rows++
+
+		if rows == options.batchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// sqlColumns lists the columns of the struct's fields.
+//
+// Nested structs are flattened into columns prefixed with the field's column. E.g. address_street.
+// The fields of embedded structs are always flattened and aren't prefixed.
+func (p *Plan) sqlColumns(t reflect.Type, prefix string, parentName string, get func(reflect.Value) reflect.Value, opts sqlOptions, depth int) []sqlColumn {
+	var columns []sqlColumn
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // Skip private fields
+		}
+
+		qualifiedName := distinctFileName(parentName, field.Name)
+		name, tagged, ok := sqlColumnName(field)
+		if !ok || p.isOmittedField(field, qualifiedName) {
+			continue
+		}
+
+		index := i
+		value := func(item reflect.Value) reflect.Value {
+			parent := get(item)
+			if !parent.IsValid() {
+				return parent
+			}
+
+			return derefValue(parent.Field(index))
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if prefix != "" {
+			name = prefix + "_" + name
+		}
+
+		isEmbedded := field.Anonymous && !tagged
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType && (!opts.nestedJSON || isEmbedded) && depth < maxFieldPathDepth {
+			nestedPrefix := name
+			if isEmbedded {
+				nestedPrefix = prefix
+			}
+
+			columns = append(columns, p.sqlColumns(fieldType, nestedPrefix, qualifiedName, value, opts, depth+1)...)
+			continue
+		}
+
+		columns = append(columns, sqlColumn{name: name, value: value})
+	}
+
+	return columns
+}
+
+// sqlColumnName returns the field's column from the db or gorm tags, or the snake_cased field name.
+// tagged is true when the name is from a tag and ok is false for the fields that are skipped with "-"
+func sqlColumnName(field reflect.StructField) (name string, tagged bool, ok bool) {
+	if tag, found := field.Tag.Lookup("db"); found {
+		name, _, _ = strings.Cut(tag, ",")
+		if name == "-" {
+			return "", false, false
+		}
+		if name != "" {
+			return name, true, true
+		}
+	}
+
+	if tag, found := field.Tag.Lookup("gorm"); found {
+		for _, setting := range strings.Split(tag, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(setting), ":")
+			switch {
+			case key == "-":
+				return "", false, false
+			case strings.EqualFold(key, "column") && value != "":
+				return value, true, true
+			}
+		}
+	}
+
+	return snakeCase(field.Name), false, true
+}
+
+// snakeCase converts a field name to snake_case. Acronyms are kept together. E.g. UserID is user_id and HTTPServer is http_server
+func snakeCase(name string) string {
+	runes := []rune(name)
+
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				sb.WriteRune('_')
+			}
+		}
+
+		sb.WriteRune(unicode.ToLower(r))
+	}
+
+	return sb.String()
+}
+
+// derefValue follows the pointers. Nil pointers give an invalid value
+func derefValue(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// quoteIdentifier quotes a table or column name
+func (d SQLDialect) quoteIdentifier(name string) string {
+	if d == MySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteTable quotes each part of a schema qualified table. E.g. public.users
+func (d SQLDialect) quoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = d.quoteIdentifier(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// quoteString quotes a string literal. MySQL also treats backslashes as escapes
+func (d SQLDialect) quoteString(s string) string {
+	if d == MySQL {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// literal formats the value for the dialect.
+// Structs, slices, arrays and maps are JSON strings. Invalid values, nil values and NaN are NULL.
+// The error is from encoding/json. E.g. for a map[bool]int
+func (d SQLDialect) literal(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return "NULL", nil
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Interface:
+		if v.IsNil() {
+			return "NULL", nil
+		}
+	}
+
+	if v.Type() == timeType {
+		return d.quoteString(v.Interface().(time.Time).UTC().Format(sqlTimeLayout)), nil
+	}
+
+	switch {
+	case v.Kind() == reflect.Bool:
+		if d == SQLite {
+			return map[bool]string{true: "1", false: "0"}[v.Bool()], nil
+		}
+		return strings.ToUpper(strconv.FormatBool(v.Bool())), nil
+
+	case v.CanInt():
+		return strconv.FormatInt(v.Int(), 10), nil
+
+	case v.CanUint():
+		return strconv.FormatUint(v.Uint(), 10), nil
+
+	case v.CanFloat():
+		if math.IsNaN(v.Float()) || math.IsInf(v.Float(), 0) {
+			return "NULL", nil
+		}
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+
+	case v.Kind() == reflect.String:
+		return d.quoteString(v.String()), nil
+	}
+
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	return d.quoteString(string(b)), nil
+}
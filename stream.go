@@ -0,0 +1,111 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// errStopIteration is returned to Plan.each(...) when the consumer stops reading the items
+var errStopIteration = errors.New("salem: iteration stopped")
+
+// Stream generates the mocks one at a time and sends them to the channel.
+// Unlike Execute, the items aren't kept in memory so it can be used for very large or unbounded runs. See WithUnboundedItems().
+//
+// The channel is closed when the items are generated, ctx is done or a mock can't be generated. Check Err() once it's closed.
+// A panic while generating, e.g. in an EnsureDerived(...) func, also closes the channel and is reported by Err().
+// Cancel ctx when you stop reading early, otherwise the goroutine generating the items is blocked.
+// The factory must not be used until the channel is closed.
+// Example:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//
+//	for item := range salem.Mock(examples.Person{}).WithUnboundedItems().Stream(ctx) {
+//		...
+//	}
+func (f *Factory) Stream(ctx context.Context) <-chan interface{} {
+	return streamItems(ctx, f, func(item interface{}) interface{} { return item })
+}
+
+// All returns an iterator that generates the mocks one at a time. The iterator can be used as an iter.Seq[interface{}].
+// Example:
+//
+//	for item := range salem.Mock(examples.Person{}).WithExactItems(1000000).All() {
+//		...
+//	}
+//
+// Breaking out of the loop stops the generation. Check Err() after the loop.
+func (f *Factory) All() func(yield func(item interface{}) bool) {
+	return func(yield func(item interface{}) bool) {
+		f.streamErr = f.iterate(context.Background(), yield)
+	}
+}
+
+// Err returns the error that stopped the most recent Stream(...) or All().
+//
+// The error is ctx.Err() when the stream's context is done, one of the ExecuteE errors, or the panic that stopped Stream(...).
+// It is nil when all the items were generated or the loop over All() was stopped early.
+func (f *Factory) Err() error {
+	return f.streamErr
+}
+
+// iterate passes the items to yield until it returns false or ctx is done
+func (f *Factory) iterate(ctx context.Context, yield func(item interface{}) bool) (err error) {
+	defer recoverError(&err)
+
+	err = f.plan.each(f, func(item interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !yield(item) {
+			return errStopIteration
+		}
+		return nil
+	})
+
+	if errors.Is(err, errStopIteration) {
+		return ctx.Err()
+	}
+
+	return err
+}
+
+// streamItems generates the items in a goroutine and sends them to the channel as T
+func streamItems[T any](ctx context.Context, f *Factory, convert func(item interface{}) T) <-chan T {
+	items := make(chan T)
+
+	go func() {
+		defer close(items)
+		defer func() {
+			// A panic can't be recovered by the reader, so it ends the stream with an error instead. E.g. from an EnsureDerived(...) func
+			if r := recover(); r != nil {
+				f.streamErr = panicError(r)
+			}
+		}()
+
+		f.streamErr = f.iterate(ctx, func(item interface{}) bool {
+			select {
+			case items <- convert(item):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	return items
+}
+
+// panicError converts a recovered panic into an error. Errors are wrapped so that they can be found with errors.As(...)
+func panicError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return fmt.Errorf("salem: the stream panicked: %w", err)
+	}
+
+	return fmt.Errorf("salem: the stream panicked: %v", r)
+}
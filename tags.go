@@ -0,0 +1,504 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// salemTagKey is the struct tag read by the plan. E.g. `salem:"min=1,max=5"`
+const salemTagKey = "salem"
+
+// Defaults used when only one of the min= or max= bounds is set
+const (
+	defaultIntSpan    = math.MaxInt8 - 1 // Matches the [0, 127) range of the int generator
+	defaultFloatSpan  = 1.0              // Matches the [0, 1) range of the float generator
+	defaultStringSpan = 50               // Matches the range of the string generator
+)
+
+// tagDirective is a single directive of a struct tag. E.g. min=3
+type tagDirective struct {
+	name  string
+	value string
+}
+
+// tagRule holds the directives of a field's struct tag.
+//
+// The factory options set on the same field path take precedence over the rule.
+type tagRule struct {
+	omit bool
+
+	nilChance float64 // the chance that a pointer, slice, map or interface is left nil
+
+	hasItems bool // the item count of a slice or map is within [minItems, maxItems]
+	minItems int
+	maxItems int
+
+	// valueType is the type generated by generator. It is the field's type, or the element
+	// type of a pointer, slice, array or map field.
+	valueType reflect.Type
+	generator func(p *Plan) interface{}
+}
+
+// parseSalemTag splits the tag into its comma separated directives.
+// A regex= directive takes the rest of the tag so that the expression can contain commas.
+func parseSalemTag(tag string) []tagDirective {
+	var directives []tagDirective
+
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name == "" {
+			continue
+		}
+
+		directives = append(directives, tagDirective{name: name, value: value})
+	}
+
+	return directives
+}
+
+// fieldTagRule returns the rule for the field's struct tag.
+// The rule is parsed once and stored by the field's qualified name so that nested plans can use it.
+func (p *Plan) fieldTagRule(field reflect.StructField, qualifiedName string) *tagRule {
+	if rule, ok := p.tagRules[qualifiedName]; ok {
+		return rule
+	}
+
+	directives, _, tagKey := p.fieldDirectives(field)
+
+	rule, err := buildTagRule(directives, field.Type)
+	if err != nil && tagKey == validatorTagKey {
+		rule = nil // The validate tag is only a hint. Validate() reports the rules that can't be used
+	} else if err != nil {
+		panic(&FieldPathError{FieldName: qualifiedName, Reason: fmt.Sprintf("Invalid %v tag: %v", salemTagKey, err)})
+	}
+
+	p.tagRules[qualifiedName] = rule
+
+	return rule
+}
+
+// tagRuleFor returns the rule for the field. Array elements (e.g. Hash[0]) use the rule of the array.
+func (p *Plan) tagRuleFor(qualifiedName string) *tagRule {
+	if index := strings.LastIndex(qualifiedName, "."); index >= 0 {
+		if elem := strings.Index(qualifiedName[index:], "["); elem >= 0 {
+			qualifiedName = qualifiedName[:index+elem]
+		}
+	} else if elem := strings.Index(qualifiedName, "["); elem >= 0 {
+		qualifiedName = qualifiedName[:elem]
+	}
+
+	return p.tagRules[qualifiedName]
+}
+
+// tagGenerator returns the generator set by the field's tag for values of fieldType
+func (p *Plan) tagGenerator(fieldType reflect.Type, qualifiedName string) GenType {
+	rule := p.tagRuleFor(qualifiedName)
+	if rule == nil || rule.generator == nil || rule.valueType != fieldType {
+		return nil
+	}
+
+	return func() interface{} {
+		return rule.generator(p)
+	}
+}
+
+// isLeftNil returns true when the field's nil= directive leaves the field nil
+func (p *Plan) isLeftNil(rule *tagRule) bool {
+	return rule.nilChance > 0 && p.rng.Float64() < rule.nilChance
+}
+
+// itemCount returns a count within the rule's items= range
+func (rule *tagRule) itemCount(p *Plan) int {
+	return rule.minItems + p.rng.Intn(rule.maxItems-rule.minItems+1)
+}
+
+// hasFieldOption returns true when the field's value is set by one of the factory options
+func (p *Plan) hasFieldOption(qualifiedName string) bool {
+	setter := p.ensuredFields[qualifiedName]
+
+	return setter.fieldAction != nil || setter.factoryAction != nil || setter.fieldSequenceAction != nil ||
+		p.fieldHandlers[qualifiedName] != nil || p.patternFields[qualifiedName] != nil ||
+		p.choiceFields[qualifiedName] != nil || p.distributionFields[qualifiedName] != nil
+}
+
+// buildTagRule creates the rule for a field of type fieldType from the tag's directives.
+// A nil rule is returned when there are no directives.
+func buildTagRule(directives []tagDirective, fieldType reflect.Type) (*tagRule, error) {
+	if len(directives) == 0 {
+		return nil, nil
+	}
+
+	rule := &tagRule{valueType: tagValueType(fieldType)}
+	values := make(map[string]string)
+
+	for _, d := range directives {
+		if _, ok := values[d.name]; ok {
+			return nil, fmt.Errorf("'%v' is set more than once", d.name)
+		}
+		values[d.name] = d.value
+
+		var err error
+		switch d.name {
+		case "omit":
+			rule.omit = true
+
+		case "nil":
+			err = rule.setNilChance(d.value, fieldType)
+
+		case "items":
+			err = rule.setItems(d.value, fieldType)
+
+		case "min", "max", "len", "oneof", "regex", "gen":
+			// Combined below since they depend on each other
+
+		default:
+			err = fmt.Errorf("unknown directive '%v'", d.name)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	generator, err := valueGenerator(values, rule.valueType)
+	if err != nil {
+		return nil, err
+	}
+	rule.generator = generator
+
+	return rule, nil
+}
+
+// tagValueType returns the type that the value directives apply to
+func tagValueType(fieldType reflect.Type) reflect.Type {
+	switch fieldType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return tagValueType(fieldType.Elem())
+	}
+
+	return fieldType
+}
+
+func (rule *tagRule) setNilChance(value string, fieldType reflect.Type) error {
+	switch fieldType.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+	default:
+		return fmt.Errorf("'nil' can't be used on a %v field", fieldType.Kind())
+	}
+
+	chance, err := strconv.ParseFloat(value, 64)
+	if err != nil || chance < 0 || chance > 1 {
+		return fmt.Errorf("'nil=%v' must be a number in [0, 1]", value)
+	}
+	rule.nilChance = chance
+
+	return nil
+}
+
+// setItems parses items=n or items=min..max
+func (rule *tagRule) setItems(value string, fieldType reflect.Type) error {
+	if k := fieldType.Kind(); k != reflect.Slice && k != reflect.Map {
+		return fmt.Errorf("'items' can't be used on a %v field", k)
+	}
+
+	from, to, isRange := strings.Cut(value, "..")
+	if !isRange {
+		to = from
+	}
+
+	min, minErr := strconv.Atoi(from)
+	max, maxErr := strconv.Atoi(to)
+	if minErr != nil || maxErr != nil || min < 0 || max < min {
+		return fmt.Errorf("'items=%v' must be n or min..max", value)
+	}
+
+	rule.hasItems = true
+	rule.minItems = min
+	rule.maxItems = max
+
+	return nil
+}
+
+// valueGenerator combines the min=, max=, len=, oneof=, regex= and gen= directives into a generator for t.
+// A nil generator is returned when none of the directives are set.
+func valueGenerator(values map[string]string, t reflect.Type) (func(p *Plan) interface{}, error) {
+	var set []string
+	for _, name := range []string{"oneof", "regex", "gen", "len", "min", "max"} {
+		if _, ok := values[name]; ok {
+			set = append(set, name)
+		}
+	}
+
+	if len(set) == 0 {
+		return nil, nil
+	}
+
+	if !isPrimitiveKind(t) {
+		return nil, fmt.Errorf("'%v' can't be used on values of type %v", set[0], t)
+	}
+
+	if oneof, ok := values["oneof"]; ok {
+		if len(set) > 1 {
+			return nil, fmt.Errorf("'oneof' can't be combined with '%v'", set[1])
+		}
+		return oneOfGenerator(strings.Split(oneof, "|"), t)
+	}
+
+	if t.Kind() == reflect.String {
+		return stringGenerator(values, set)
+	}
+
+	if t.Kind() == reflect.Bool {
+		return nil, fmt.Errorf("'%v' can't be used on a bool", set[0])
+	}
+
+	if set[0] != "min" && set[0] != "max" {
+		return nil, fmt.Errorf("'%v' can only be used on strings", set[0])
+	}
+
+	min, hasMin := values["min"]
+	max, hasMax := values["max"]
+
+	return rangeGenerator(t, min, hasMin, max, hasMax)
+}
+
+// oneOfGenerator picks one of the options converted to type t
+func oneOfGenerator(options []string, t reflect.Type) (func(p *Plan) interface{}, error) {
+	values := make([]reflect.Value, len(options))
+	for i, option := range options {
+		val, err := parseValue(option, t)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = val
+	}
+
+	return func(p *Plan) interface{} {
+		return values[p.rng.Intn(len(values))].Interface()
+	}, nil
+}
+
+// stringGenerator creates the generator for the regex=, gen=, len=, min= and max= directives of a string
+func stringGenerator(values map[string]string, set []string) (func(p *Plan) interface{}, error) {
+	if pattern, ok := values["regex"]; ok {
+		if len(set) > 1 {
+			return nil, fmt.Errorf("'regex' can't be combined with '%v'", set[1])
+		}
+
+		g, err := newPatternGenerator(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("'regex=%v' is invalid: %v", pattern, err)
+		}
+
+		return func(p *Plan) interface{} { return g.generate(p.rng) }, nil
+	}
+
+	if name, ok := values["gen"]; ok {
+		if len(set) > 1 {
+			return nil, fmt.Errorf("'gen' can't be combined with '%v'", set[1])
+		}
+
+		gen := namedGenerators[name]
+		if gen == nil {
+			return nil, fmt.Errorf("'gen=%v' is not a known generator", name)
+		}
+
+		return func(p *Plan) interface{} { return gen(p) }, nil
+	}
+
+	minLen, maxLen, err := stringLength(values)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(p *Plan) interface{} {
+		return randCharacters(p.rng, int(p.randInt64Range(int64(minLen), int64(maxLen))))
+	}, nil
+}
+
+// stringLength returns the length range set by len= or min= and max=
+func stringLength(values map[string]string) (int, int, error) {
+	parse := func(name string, fallback int) (int, error) {
+		value, ok := values[name]
+		if !ok {
+			return fallback, nil
+		}
+
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("'%v=%v' must be a length", name, value)
+		}
+		return n, nil
+	}
+
+	if _, ok := values["len"]; ok {
+		if _, hasMin := values["min"]; hasMin {
+			return 0, 0, fmt.Errorf("'len' can't be combined with 'min'")
+		}
+		if _, hasMax := values["max"]; hasMax {
+			return 0, 0, fmt.Errorf("'len' can't be combined with 'max'")
+		}
+
+		n, err := parse("len", 0)
+		return n, n, err
+	}
+
+	min, err := parse("min", 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	max, err := parse("max", min+defaultStringSpan)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if max < min {
+		return 0, 0, fmt.Errorf("'max=%v' is less than 'min=%v'", max, min)
+	}
+
+	return min, max, nil
+}
+
+// rangeGenerator generates numbers of type t within [min, max].
+// When only one of the bounds is set the other is based on the range of the default generators.
+func rangeGenerator(t reflect.Type, min string, hasMin bool, max string, hasMax bool) (func(p *Plan) interface{}, error) {
+	if !hasMin {
+		min = "0"
+	}
+
+	lo, err := parseValue(min, t)
+	if err != nil {
+		return nil, err
+	}
+
+	hi := lo
+	if hasMax {
+		if hi, err = parseValue(max, t); err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		from, to := lo.Float(), hi.Float()
+		switch {
+		case !hasMax:
+			to = from + defaultFloatSpan
+		case !hasMin:
+			from = math.Min(0, to-defaultFloatSpan)
+		}
+		if to < from {
+			return nil, fmt.Errorf("'max=%v' is less than 'min=%v'", max, min)
+		}
+
+		return func(p *Plan) interface{} {
+			return reflect.ValueOf(p.randFloat64Range(from, to)).Convert(t).Interface()
+		}, nil
+
+	case lo.CanInt():
+		from, to := lo.Int(), hi.Int()
+		kindMin, kindMax := intBounds(t)
+		switch {
+		case !hasMax:
+			to = kindMax
+			if from <= kindMax-defaultIntSpan {
+				to = from + defaultIntSpan
+			}
+		case !hasMin:
+			from = 0
+			if to < 0 {
+				from = kindMin
+				if to >= kindMin+defaultIntSpan {
+					from = to - defaultIntSpan
+				}
+			}
+		}
+		if to < from {
+			return nil, fmt.Errorf("'max=%v' is less than 'min=%v'", max, min)
+		}
+
+		return func(p *Plan) interface{} {
+			return reflect.ValueOf(p.randInt64Range(from, to)).Convert(t).Interface()
+		}, nil
+	}
+
+	from, to := lo.Uint(), hi.Uint()
+	if !hasMax {
+		to = math.MaxUint64 >> (64 - t.Bits())
+		if from <= to-defaultIntSpan {
+			to = from + defaultIntSpan
+		}
+	}
+	if to < from {
+		return nil, fmt.Errorf("'max=%v' is less than 'min=%v'", max, min)
+	}
+
+	return func(p *Plan) interface{} {
+		return reflect.ValueOf(p.randUint64Range(from, to)).Convert(t).Interface()
+	}, nil
+}
+
+// intBounds returns the smallest and largest values of a signed integer type
+func intBounds(t reflect.Type) (int64, int64) {
+	max := int64(math.MaxInt64 >> (64 - t.Bits()))
+
+	return -max - 1, max
+}
+
+// parseValue parses the directive's value as a value of the primitive type t
+func parseValue(value string, t reflect.Type) (reflect.Value, error) {
+	val := reflect.New(t).Elem()
+
+	var err error
+	switch kindFamily(t.Kind()) {
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(value); err == nil {
+			val.SetBool(b)
+		}
+
+	case reflect.String:
+		val.SetString(value)
+
+	default:
+		switch {
+		case val.CanInt():
+			var n int64
+			if n, err = strconv.ParseInt(value, 10, t.Bits()); err == nil {
+				val.SetInt(n)
+			}
+
+		case val.CanUint():
+			var n uint64
+			if n, err = strconv.ParseUint(value, 10, t.Bits()); err == nil {
+				val.SetUint(n)
+			}
+
+		default:
+			var f float64
+			if f, err = strconv.ParseFloat(value, t.Bits()); err == nil {
+				val.SetFloat(f)
+			}
+		}
+	}
+
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("'%v' is not a valid %v", value, t)
+	}
+
+	return val, nil
+}
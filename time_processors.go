@@ -0,0 +1,105 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"reflect"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// defaultTimeRange is used for time.Time fields that weren't set with WithTimeRange(...).
+// The range is fixed so that seeded runs generate the same times.
+var defaultTimeRange = timeRange{
+	from: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+	to:   time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+}
+
+// maxGeneratedDuration is the upper bounds for generated time.Duration values
+const maxGeneratedDuration = 24 * time.Hour
+
+type timeRange struct {
+	from time.Time
+	to   time.Time
+}
+
+// initTypeProcessors sets up the processors for types that can't be generated from their kind
+func (p *Plan) initTypeProcessors() {
+	p.typeProcessors = make(map[reflect.Type]processorType)
+
+	p.typeProcessors[timeType] = onTime(p)
+	p.typeProcessors[durationType] = onDuration(p)
+}
+
+func onTime(p *Plan) processorType {
+	return func(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
+		if generator != nil {
+			val := generator()
+			return reflect.ValueOf(val)
+		}
+
+		return reflect.ValueOf(p.generateTime(qualifiedName))
+	}
+}
+
+func onDuration(p *Plan) processorType {
+	return func(generator GenType, fieldType reflect.Type, itemIndex int, qualifiedName string) reflect.Value {
+		if generator != nil {
+			val := generator()
+			return convertValue(reflect.ValueOf(val), fieldType)
+		}
+
+		return reflect.ValueOf(time.Duration(p.rng.Int63n(int64(maxGeneratedDuration))))
+	}
+}
+
+// generateTime returns a time within the field's time range
+func (p *Plan) generateTime(qualifiedName string) time.Time {
+	tr, ok := p.timeRanges[qualifiedName]
+	if !ok {
+		tr = defaultTimeRange
+	}
+
+	if maxStep, ok := p.monotonicTimes[qualifiedName]; ok {
+		return p.nextMonotonicTime(qualifiedName, tr.from, maxStep)
+	}
+
+	return tr.randTime(p).In(p.timeLocation)
+}
+
+// nextMonotonicTime returns a time that is up to maxStep after the previous time generated for the field.
+// The first time is the start of the field's time range.
+func (p *Plan) nextMonotonicTime(qualifiedName string, from time.Time, maxStep time.Duration) time.Time {
+	next := from
+
+	if last, ok := p.state.lastTimes[qualifiedName]; ok {
+		step := time.Duration(1)
+		if maxStep > 1 {
+			step += time.Duration(p.rng.Int63n(int64(maxStep)))
+		}
+		next = last.Add(step)
+	}
+
+	p.state.lastTimes[qualifiedName] = next
+
+	return next.In(p.timeLocation)
+}
+
+// randTime returns a random time in [from, to).
+// Seconds are used for the offset so that the range can span more than the 290 years a time.Duration holds.
+func (tr timeRange) randTime(p *Plan) time.Time {
+	seconds := tr.to.Unix() - tr.from.Unix()
+	if seconds <= 0 {
+		return tr.from
+	}
+
+	offset := p.rng.Int63n(seconds)
+	nanos := p.rng.Int63n(int64(time.Second))
+
+	return time.Unix(tr.from.Unix()+offset, nanos)
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"reflect"
+	"sync"
+)
+
+// typeRegistry holds the type generators and enums shared by all factories
+var typeRegistry = struct {
+	sync.RWMutex
+	generators map[reflect.Type]GenType
+	enums      map[reflect.Type]*valueChoice
+}{generators: make(map[reflect.Type]GenType), enums: make(map[reflect.Type]*valueChoice)}
+
+// RegisterTypeGenerator sets the generator used for every value of type t by all factories.
+// E.g. RegisterTypeGenerator(reflect.TypeOf(uuid.UUID{}), func() interface{} { return uuid.New() })
+//
+// The generator is used for fields, slice elements, map keys and values, and pointer targets of type t.
+// Generators registered with Factory.RegisterTypeGenerator(...) take precedence.
+func RegisterTypeGenerator(t reflect.Type, generator GenType) {
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+
+	typeRegistry.generators[t] = generator
+}
+
+// UnregisterTypeGenerator removes the generator set with RegisterTypeGenerator(...)
+func UnregisterTypeGenerator(t reflect.Type) {
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+
+	delete(typeRegistry.generators, t)
+}
+
+// RegisterEnum limits every value of type t to one of the values for all factories.
+// E.g. RegisterEnum(reflect.TypeOf(Status("")), "active", "suspended", "closed")
+//
+// The values are converted to t. When a value isn't a t, Validate() reports the fields of type t and ExecuteE returns
+// an *UnsupportedKindError. Like RegisterTypeGenerator(...) the enum
+// is used for fields, slice elements, map keys and values, and pointer targets of type t.
+// The generators and enums registered with the factory take precedence.
+func RegisterEnum(t reflect.Type, values ...interface{}) {
+	enum := newEnumChoice(t, values)
+
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+
+	typeRegistry.enums[t] = enum
+}
+
+// UnregisterEnum removes the enum set with RegisterEnum(...)
+func UnregisterEnum(t reflect.Type) {
+	typeRegistry.Lock()
+	defer typeRegistry.Unlock()
+
+	delete(typeRegistry.enums, t)
+}
+
+// RegisterTypeGenerator sets the generator used for every value of type t generated by the plan
+func (p *Plan) RegisterTypeGenerator(t reflect.Type, generator GenType) {
+	p.typeGenerators[t] = generator
+}
+
+// GetTypeGenerator returns the generator for type t.
+// The plan's generators, enums and distributions are checked before the generators and enums registered for all factories.
+func (p *Plan) GetTypeGenerator(t reflect.Type) GenType {
+	if generator := p.typeGenerators[t]; generator != nil {
+		return generator
+	}
+
+	if enum := p.enums[t]; enum != nil && enum.err == nil {
+		return p.choiceGenerator(enum)
+	}
+
+	if dist := p.distributions[t]; dist != nil && isNumericType(t) {
+		return p.distributionGenerator(dist, t)
+	}
+
+	typeRegistry.RLock()
+	defer typeRegistry.RUnlock()
+
+	if generator := typeRegistry.generators[t]; generator != nil {
+		return generator
+	}
+
+	if enum := typeRegistry.enums[t]; enum != nil && enum.err == nil {
+		return p.choiceGenerator(enum)
+	}
+
+	return nil
+}
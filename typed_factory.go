@@ -0,0 +1,399 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+// TypedFactory wraps a Factory so that the mocks are returned as T instead of interface{}
+type TypedFactory[T any] struct {
+	factory *Factory
+}
+
+// For creates a typed factory for T.
+//
+// By default For is configured to generate 1 mock.
+// Example:
+//
+//	people := salem.For[examples.Person]().WithExactItems(5).Execute() //<- people is []examples.Person
+func For[T any]() *TypedFactory[T] {
+	var t T
+
+	return &TypedFactory[T]{factory: Mock(t)}
+}
+
+// Factory returns the untyped factory used to generate the mocks
+func (tf *TypedFactory[T]) Factory() *Factory {
+	return tf.factory
+}
+
+// GetPlan return a point to the current plan
+func (tf *TypedFactory[T]) GetPlan() *Plan {
+	return tf.factory.GetPlan()
+}
+
+// Execute execute the factory instructions to generate the mocks
+func (tf *TypedFactory[T]) Execute() []T {
+	return toTypedSlice[T](tf.factory.Execute())
+}
+
+// ExecuteE see Factory.ExecuteE
+func (tf *TypedFactory[T]) ExecuteE() ([]T, error) {
+	results, err := tf.factory.ExecuteE()
+	if err != nil {
+		return nil, err
+	}
+
+	return toTypedSlice[T](results), nil
+}
+
+// WriteCSV see Factory.WriteCSV
+func (tf *TypedFactory[T]) WriteCSV(w io.Writer, opts CSVOptions) error {
+	return tf.factory.WriteCSV(w, opts)
+}
+
+// WriteJSON see Factory.WriteJSON
+func (tf *TypedFactory[T]) WriteJSON(w io.Writer, opts JSONOptions) error {
+	return tf.factory.WriteJSON(w, opts)
+}
+
+// WriteJSONLines see Factory.WriteJSONLines
+func (tf *TypedFactory[T]) WriteJSONLines(w io.Writer) error {
+	return tf.factory.WriteJSONLines(w)
+}
+
+// WriteSQL see Factory.WriteSQL
+func (tf *TypedFactory[T]) WriteSQL(w io.Writer, table string, dialect SQLDialect, opts ...SQLOption) error {
+	return tf.factory.WriteSQL(w, table, dialect, opts...)
+}
+
+// WriteGoLiteral see Factory.WriteGoLiteral
+func (tf *TypedFactory[T]) WriteGoLiteral(w io.Writer, varName string, opts ...GoLiteralOption) error {
+	return tf.factory.WriteGoLiteral(w, varName, opts...)
+}
+
+// Stream see Factory.Stream
+func (tf *TypedFactory[T]) Stream(ctx context.Context) <-chan T {
+	return streamItems(ctx, tf.factory, toType[T])
+}
+
+// All returns an iterator over the mocks. It can be used as an iter.Seq[T]. See Factory.All
+func (tf *TypedFactory[T]) All() func(yield func(item T) bool) {
+	return func(yield func(item T) bool) {
+		tf.factory.All()(func(item interface{}) bool {
+			return yield(toType[T](item))
+		})
+	}
+}
+
+// Err see Factory.Err
+func (tf *TypedFactory[T]) Err() error {
+	return tf.factory.Err()
+}
+
+// ExecuteN generates exactly n mocks without changing the configured item count
+func (tf *TypedFactory[T]) ExecuteN(n int) []T {
+	plan := tf.factory.plan
+	itemCountAction := plan.evalItemCountAction
+	defer plan.SetItemCountHandler(itemCountAction)
+
+	tf.factory.WithExactItems(n)
+
+	return tf.Execute()
+}
+
+// One generates a single mock without changing the configured item count
+func (tf *TypedFactory[T]) One() T {
+	return tf.ExecuteN(1)[0]
+}
+
+// Validate see Factory.Validate
+func (tf *TypedFactory[T]) Validate() error {
+	return tf.factory.Validate()
+}
+
+// WithSeed see Factory.WithSeed
+func (tf *TypedFactory[T]) WithSeed(seed int64) *TypedFactory[T] {
+	tf.factory.WithSeed(seed)
+
+	return tf
+}
+
+// Seed see Factory.Seed
+func (tf *TypedFactory[T]) Seed() int64 {
+	return tf.factory.Seed()
+}
+
+// Omit see Factory.Omit
+func (tf *TypedFactory[T]) Omit(fieldName string) *TypedFactory[T] {
+	tf.factory.Omit(fieldName)
+
+	return tf
+}
+
+// Ensure see Factory.Ensure
+func (tf *TypedFactory[T]) Ensure(fieldName string, sharedValue interface{}) *TypedFactory[T] {
+	tf.factory.Ensure(fieldName, sharedValue)
+
+	return tf
+}
+
+// EnsureConstraint see Factory.EnsureConstraint
+func (tf *TypedFactory[T]) EnsureConstraint(fieldName string, constraint FieldConstraint) *TypedFactory[T] {
+	tf.factory.EnsureConstraint(fieldName, constraint)
+
+	return tf
+}
+
+// EnsureItem see Factory.EnsureItem
+func (tf *TypedFactory[T]) EnsureItem(isValid func(item T) bool) *TypedFactory[T] {
+	tf.factory.EnsureItem(func(item interface{}) bool {
+		return isValid(toType[T](item))
+	})
+
+	return tf
+}
+
+// EnsureDerived see Factory.EnsureDerived
+func (tf *TypedFactory[T]) EnsureDerived(fieldName string, derive func(item T) interface{}) *TypedFactory[T] {
+	tf.factory.EnsureDerived(fieldName, func(item interface{}) interface{} {
+		return derive(toType[T](item))
+	})
+
+	return tf
+}
+
+// EnsureUnique see Factory.EnsureUnique
+func (tf *TypedFactory[T]) EnsureUnique(fieldNames ...string) *TypedFactory[T] {
+	tf.factory.EnsureUnique(fieldNames...)
+
+	return tf
+}
+
+// EnsureUniqueAcrossExecutions see Factory.EnsureUniqueAcrossExecutions
+func (tf *TypedFactory[T]) EnsureUniqueAcrossExecutions(fieldNames ...string) *TypedFactory[T] {
+	tf.factory.EnsureUniqueAcrossExecutions(fieldNames...)
+
+	return tf
+}
+
+// EnsureSequence see Factory.EnsureSequence
+func (tf *TypedFactory[T]) EnsureSequence(fieldName string, seq ...interface{}) *TypedFactory[T] {
+	tf.factory.EnsureSequence(fieldName, seq...)
+
+	return tf
+}
+
+// EnsureSequenceAcross see Factory.EnsureSequenceAcross
+func (tf *TypedFactory[T]) EnsureSequenceAcross(fieldName string, seq ...interface{}) *TypedFactory[T] {
+	tf.factory.EnsureSequenceAcross(fieldName, seq...)
+
+	return tf
+}
+
+// EnsureOneOf see Factory.EnsureOneOf
+func (tf *TypedFactory[T]) EnsureOneOf(fieldName string, values ...interface{}) *TypedFactory[T] {
+	tf.factory.EnsureOneOf(fieldName, values...)
+
+	return tf
+}
+
+// EnsureWeighted see Factory.EnsureWeighted
+func (tf *TypedFactory[T]) EnsureWeighted(fieldName string, weighted map[interface{}]int) *TypedFactory[T] {
+	tf.factory.EnsureWeighted(fieldName, weighted)
+
+	return tf
+}
+
+// EnsureMapKeyOneOf see Factory.EnsureMapKeyOneOf
+func (tf *TypedFactory[T]) EnsureMapKeyOneOf(fieldName string, values ...interface{}) *TypedFactory[T] {
+	tf.factory.EnsureMapKeyOneOf(fieldName, values...)
+
+	return tf
+}
+
+// EnsureMapKeyWeighted see Factory.EnsureMapKeyWeighted
+func (tf *TypedFactory[T]) EnsureMapKeyWeighted(fieldName string, weighted map[interface{}]int) *TypedFactory[T] {
+	tf.factory.EnsureMapKeyWeighted(fieldName, weighted)
+
+	return tf
+}
+
+// EnsureDistribution see Factory.EnsureDistribution
+func (tf *TypedFactory[T]) EnsureDistribution(fieldName string, dist Distribution) *TypedFactory[T] {
+	tf.factory.EnsureDistribution(fieldName, dist)
+
+	return tf
+}
+
+// EnsurePattern see Factory.EnsurePattern
+func (tf *TypedFactory[T]) EnsurePattern(fieldName string, pattern *regexp.Regexp) *TypedFactory[T] {
+	tf.factory.EnsurePattern(fieldName, pattern)
+
+	return tf
+}
+
+// EnsureMapKeyPattern see Factory.EnsureMapKeyPattern
+func (tf *TypedFactory[T]) EnsureMapKeyPattern(fieldName string, pattern *regexp.Regexp) *TypedFactory[T] {
+	tf.factory.EnsureMapKeyPattern(fieldName, pattern)
+
+	return tf
+}
+
+// EnsureMapValuePattern see Factory.EnsureMapValuePattern
+func (tf *TypedFactory[T]) EnsureMapValuePattern(fieldName string, pattern *regexp.Regexp) *TypedFactory[T] {
+	tf.factory.EnsureMapValuePattern(fieldName, pattern)
+
+	return tf
+}
+
+// EnsureMapKeySequence see Factory.EnsureMapKeySequence
+func (tf *TypedFactory[T]) EnsureMapKeySequence(fieldName string, seq ...interface{}) *TypedFactory[T] {
+	tf.factory.EnsureMapKeySequence(fieldName, seq...)
+
+	return tf
+}
+
+// EnsureMapValueSequence see Factory.EnsureMapValueSequence
+func (tf *TypedFactory[T]) EnsureMapValueSequence(fieldName string, seq ...interface{}) *TypedFactory[T] {
+	tf.factory.EnsureMapValueSequence(fieldName, seq...)
+
+	return tf
+}
+
+// WithMinItems see Factory.WithMinItems
+func (tf *TypedFactory[T]) WithMinItems(n int, span ...int) *TypedFactory[T] {
+	tf.factory.WithMinItems(n, span...)
+
+	return tf
+}
+
+// WithMaxItems see Factory.WithMaxItems
+func (tf *TypedFactory[T]) WithMaxItems(n int) *TypedFactory[T] {
+	tf.factory.WithMaxItems(n)
+
+	return tf
+}
+
+// WithExactItems see Factory.WithExactItems
+func (tf *TypedFactory[T]) WithExactItems(n int) *TypedFactory[T] {
+	tf.factory.WithExactItems(n)
+
+	return tf
+}
+
+// WithUnboundedItems see Factory.WithUnboundedItems
+func (tf *TypedFactory[T]) WithUnboundedItems() *TypedFactory[T] {
+	tf.factory.WithUnboundedItems()
+
+	return tf
+}
+
+// WithExactMapItems see Factory.WithExactMapItems
+func (tf *TypedFactory[T]) WithExactMapItems(fieldName string, n int) *TypedFactory[T] {
+	tf.factory.WithExactMapItems(fieldName, n)
+
+	return tf
+}
+
+// WithMaxMapItems see Factory.WithMaxMapItems
+func (tf *TypedFactory[T]) WithMaxMapItems(fieldName string, n int) *TypedFactory[T] {
+	tf.factory.WithMaxMapItems(fieldName, n)
+
+	return tf
+}
+
+// WithMinMapItems see Factory.WithMinMapItems
+func (tf *TypedFactory[T]) WithMinMapItems(fieldName string, n int, span ...int) *TypedFactory[T] {
+	tf.factory.WithMinMapItems(fieldName, n, span...)
+
+	return tf
+}
+
+// RegisterEnum see Factory.RegisterEnum
+func (tf *TypedFactory[T]) RegisterEnum(t reflect.Type, values ...interface{}) *TypedFactory[T] {
+	tf.factory.RegisterEnum(t, values...)
+
+	return tf
+}
+
+// RegisterDistribution see Factory.RegisterDistribution
+func (tf *TypedFactory[T]) RegisterDistribution(t reflect.Type, dist Distribution) *TypedFactory[T] {
+	tf.factory.RegisterDistribution(t, dist)
+
+	return tf
+}
+
+// RegisterTypeGenerator see Factory.RegisterTypeGenerator
+func (tf *TypedFactory[T]) RegisterTypeGenerator(t reflect.Type, generator GenType) *TypedFactory[T] {
+	tf.factory.RegisterTypeGenerator(t, generator)
+
+	return tf
+}
+
+// WithTimeRange see Factory.WithTimeRange
+func (tf *TypedFactory[T]) WithTimeRange(fieldName string, from time.Time, to time.Time) *TypedFactory[T] {
+	tf.factory.WithTimeRange(fieldName, from, to)
+
+	return tf
+}
+
+// WithTimeLocation see Factory.WithTimeLocation
+func (tf *TypedFactory[T]) WithTimeLocation(loc *time.Location) *TypedFactory[T] {
+	tf.factory.WithTimeLocation(loc)
+
+	return tf
+}
+
+// WithMonotonicTime see Factory.WithMonotonicTime
+func (tf *TypedFactory[T]) WithMonotonicTime(fieldName string, maxStep time.Duration) *TypedFactory[T] {
+	tf.factory.WithMonotonicTime(fieldName, maxStep)
+
+	return tf
+}
+
+// WithValidatorTags see Factory.WithValidatorTags
+func (tf *TypedFactory[T]) WithValidatorTags() *TypedFactory[T] {
+	tf.factory.WithValidatorTags()
+
+	return tf
+}
+
+// OnField see Factory.OnField
+func (tf *TypedFactory[T]) OnField(fieldName string, handler fieldHandlerType) *TypedFactory[T] {
+	tf.factory.OnField(fieldName, handler)
+
+	return tf
+}
+
+// toTypedSlice converts the results of Plan.Run(...) to []T
+func toTypedSlice[T any](results []interface{}) []T {
+	typed := make([]T, len(results))
+
+	for i, item := range results {
+		typed[i] = toType[T](item)
+	}
+
+	return typed
+}
+
+// toType converts an item generated by Plan.Run(...) to T.
+//
+// The plan always generates values, so when T is a pointer (e.g. For[*examples.Person]())
+// the value is copied into a new pointer.
+func toType[T any](item interface{}) T {
+	if v, ok := item.(T); ok {
+		return v
+	}
+
+	ptr := reflect.New(reflect.TypeOf(item))
+	ptr.Elem().Set(reflect.ValueOf(item))
+
+	return ptr.Interface().(T)
+}
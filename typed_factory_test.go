@@ -0,0 +1,80 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem_test
+
+import (
+	"go-salem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_TypedFactory(t *testing.T) {
+	test_typed_execute(t)
+	test_typed_pointer_execute(t)
+	test_typed_execute_n(t)
+	test_typed_one(t)
+	test_typed_ensure(t)
+}
+
+func test_typed_execute(t *testing.T) {
+	results := salem.For[school]().
+		WithExactItems(3).
+		Execute()
+
+	assert.IsType(t, []school{}, results, "expect Execute() to return []T")
+	assert.Equal(t, 3, len(results), "expect WithExactItems(...) to control the item count")
+	assert.NotEmpty(t, results[0].Name, "expect fields to be mocked")
+}
+
+func test_typed_pointer_execute(t *testing.T) {
+	results := salem.For[*school]().
+		Ensure("Name", "Ridgemont").
+		WithExactItems(2).
+		Execute()
+
+	assert.IsType(t, []*school{}, results, "expect Execute() to return []*T")
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, "Ridgemont", results[1].Name, "expect pointer mocks to be populated")
+}
+
+func test_typed_execute_n(t *testing.T) {
+	f := salem.For[school]().WithExactItems(2)
+
+	assert.Equal(t, 5, len(f.ExecuteN(5)), "expect ExecuteN(...) to generate n items")
+	assert.Equal(t, 2, len(f.Execute()), "expect ExecuteN(...) to keep the configured item count")
+}
+
+func test_typed_one(t *testing.T) {
+	f := salem.For[school]().
+		Ensure("NextOfKin", "Jeff").
+		WithExactItems(4)
+
+	result := f.One()
+
+	assert.Equal(t, "Jeff", result.NextOfKin, "expect One() to apply the factory configuration")
+	assert.Equal(t, 4, len(f.Execute()), "expect One() to keep the configured item count")
+}
+
+func test_typed_ensure(t *testing.T) {
+	type wallet struct {
+		Owner string
+		Bank  string
+		Notes []money
+		Codes map[string]string
+	}
+
+	result := salem.For[wallet]().
+		EnsureSequence("Owner", "Ruth").
+		Ensure("Notes", salem.Tap().WithExactItems(3)).
+		EnsureConstraint("Bank", salem.ConstrainStringLength(3, 60)).
+		WithExactMapItems("Codes", 4).
+		OnField("Bank", func(int) interface{} { return "Bank of Salem" }).
+		One()
+
+	assert.Equal(t, "Ruth", result.Owner)
+	assert.Equal(t, 3, len(result.Notes))
+	assert.Equal(t, "Bank of Salem", result.Bank)
+	assert.Equal(t, 4, len(result.Codes))
+}
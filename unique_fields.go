@@ -0,0 +1,226 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// uniqueFields holds the values already generated for fields set via EnsureUnique.
+// It is a FieldConstraint so that it can be reported in a *ConstraintError.
+type uniqueFields struct {
+	fieldNames       []string
+	acrossExecutions bool
+	seen             map[string]bool
+}
+
+func (u *uniqueFields) IsValid(key interface{}) bool {
+	return !u.seen[key.(string)]
+}
+
+func (u *uniqueFields) String() string {
+	return fmt.Sprintf("Unique(%v)", strings.Join(u.fieldNames, ", "))
+}
+
+// uniqueMapKeys is the constraint reported when a map can't be given the requested number of distinct keys
+type uniqueMapKeys struct {
+	count int
+}
+
+func (u *uniqueMapKeys) IsValid(field interface{}) bool {
+	return reflect.ValueOf(field).Len() == u.count
+}
+
+func (u *uniqueMapKeys) String() string {
+	return fmt.Sprintf("UniqueMapKeys[%v]", u.count)
+}
+
+// AddUniqueFields ensures the combination of the fields' values is unique across the items of an execution.
+// When acrossExecutions is true the values are also unique across executions of the plan.
+func (p *Plan) AddUniqueFields(fieldNames []string, acrossExecutions bool) {
+	p.uniqueFields = append(p.uniqueFields, &uniqueFields{
+		fieldNames:       fieldNames,
+		acrossExecutions: acrossExecutions,
+		seen:             make(map[string]bool),
+	})
+}
+
+// resetUniqueFields forgets the values generated by the previous execution
+func (p *Plan) resetUniqueFields() {
+	for _, u := range p.uniqueFields {
+		if !u.acrossExecutions {
+			u.seen = make(map[string]bool)
+		}
+	}
+}
+
+// duplicateUniqueFields returns the unique fields whose values were already generated.
+// The keys of the item's values are returned so that they can be recorded once the item is accepted.
+func (p *Plan) duplicateUniqueFields(item interface{}) (FieldConstraint, []string) {
+	keys := make([]string, len(p.uniqueFields))
+
+	for i, u := range p.uniqueFields {
+		values := make([]string, len(u.fieldNames))
+		missing := false
+
+		for j, fieldName := range u.fieldNames {
+			field, ok := getFieldPath(item, strings.TrimPrefix(fieldName, p.parentName+"."))
+			values[j] = valueKey(field)
+			missing = missing || !ok
+		}
+
+		if missing {
+			continue // E.g. the path crosses a nil pointer. Like a NULL in SQL, a missing value is never a duplicate
+		}
+
+		keys[i] = strings.Join(values, "\x00")
+		if !u.IsValid(keys[i]) {
+			return u, nil
+		}
+	}
+
+	return nil, keys
+}
+
+// recordUniqueFields marks the keys returned by duplicateUniqueFields(...) as used
+func (p *Plan) recordUniqueFields(keys []string) {
+	for i, u := range p.uniqueFields {
+		if keys[i] != "" {
+			u.seen[keys[i]] = true
+		}
+	}
+}
+
+// valueKey returns a key that is the same for equal values. Pointers are compared by the values they point to,
+// including the pointers held by structs, slices and maps
+func valueKey(val reflect.Value) string {
+	var sb strings.Builder
+	writeValueKey(&sb, val, map[uintptr]bool{})
+
+	return sb.String()
+}
+
+// writeValueKey writes the key of val to sb. visited guards against pointer cycles
+func writeValueKey(sb *strings.Builder, val reflect.Value, visited map[uintptr]bool) {
+	if !val.IsValid() {
+		sb.WriteString("<nil>")
+		return
+	}
+
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			sb.WriteString("<nil>")
+			return
+		}
+		if visited[val.Pointer()] {
+			sb.WriteString("<cycle>")
+			return
+		}
+
+		visited[val.Pointer()] = true
+		writeValueKey(sb, val.Elem(), visited)
+		delete(visited, val.Pointer())
+
+	case reflect.Interface:
+		if val.IsNil() {
+			sb.WriteString("<nil>")
+			return
+		}
+		fmt.Fprintf(sb, "%v(", val.Elem().Type()) // E.g. int(1) and string("1") are different keys
+		writeValueKey(sb, val.Elem(), visited)
+		sb.WriteString(")")
+
+	case reflect.Struct:
+		fmt.Fprintf(sb, "%v{", val.Type())
+		for i := 0; i < val.NumField(); i++ {
+			sb.WriteString(val.Type().Field(i).Name + ":")
+			writeValueKey(sb, val.Field(i), visited)
+			sb.WriteString(",")
+		}
+		sb.WriteString("}")
+
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			sb.WriteString("<nil>")
+			return
+		}
+
+		sb.WriteString("[")
+		for i := 0; i < val.Len(); i++ {
+			writeValueKey(sb, val.Index(i), visited)
+			sb.WriteString(",")
+		}
+		sb.WriteString("]")
+
+	case reflect.Map:
+		if val.IsNil() {
+			sb.WriteString("<nil>")
+			return
+		}
+
+		entries := make([]string, 0, val.Len())
+		iter := val.MapRange()
+		for iter.Next() {
+			var entry strings.Builder
+			writeValueKey(&entry, iter.Key(), visited)
+			entry.WriteString(":")
+			writeValueKey(&entry, iter.Value(), visited)
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+
+		sb.WriteString("map[" + strings.Join(entries, ",") + "]")
+
+	default:
+		fmt.Fprintf(sb, "%#v", val) // fmt formats the value held by val, including unexported fields
+	}
+}
+
+// getFieldPath returns the value of the field in the item.
+// ok is false when the path crosses a nil pointer or a missing element, so the field has no value
+func getFieldPath(item interface{}, fieldName string) (field reflect.Value, ok bool) {
+	field = reflect.ValueOf(item)
+	segments := strings.Split(fieldName, ".")
+
+	for i, segment := range segments {
+		name, indexes, _ := splitElementIndexes(segment)
+
+		if field, ok = derefField(field); !ok {
+			return reflect.Value{}, false
+		}
+		if field.Kind() != reflect.Struct {
+			panic(&FieldPathError{FieldName: fieldName, Reason: fmt.Sprintf("Can't make the field unique since '%v' is a %v. Use EnsureUnique(...) on a tapped factory for it instead", strings.Join(segments[:i], "."), field.Kind())})
+		}
+
+		field = field.FieldByName(name)
+		if !field.IsValid() || !field.CanInterface() {
+			panic(&FieldPathError{FieldName: fieldName, Reason: fmt.Sprintf("Can't make the field unique since '%v' is not an exported field", name)})
+		}
+
+		for _, index := range indexes {
+			if field, ok = derefField(field); !ok || index >= field.Len() {
+				return reflect.Value{}, false
+			}
+			field = field.Index(index)
+		}
+	}
+
+	return field, true
+}
+
+// derefField follows the pointers held by field. ok is false when one of them is nil
+func derefField(field reflect.Value) (reflect.Value, bool) {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return reflect.Value{}, false
+		}
+		field = field.Elem()
+	}
+
+	return field, true
+}
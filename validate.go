@@ -0,0 +1,562 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// maxFieldPathDepth limits how deep the valid field paths are listed for recursive types
+const maxFieldPathDepth = 8
+
+// configuredPath is a field path used by one of the factory options
+type configuredPath struct {
+	fieldName string
+	option    string
+
+	// checkType returns the reason the field's type can't be used with the option.
+	// An empty string means the type is fine.
+	checkType func(fieldType reflect.Type) string
+
+	// checkPath returns the reason the resolved path can't be used with the option. E.g. it crosses a slice
+	checkPath func(rootType reflect.Type, path configuredPath) string
+
+	scope string // The field of the tapped factory that set the path. E.g. Friends for Friends.Handle
+
+	err *FieldPathError // Set when the option couldn't be set. E.g. EnsurePattern(...) with a pattern that matches nothing
+}
+
+// Validate checks the configured field paths against rootType. See Factory.Validate()
+func (p *Plan) Validate(rootType reflect.Type) error {
+	paths := p.configuredPaths(map[*Plan]bool{})
+	sort.Slice(paths, func(i, j int) bool {
+		if paths[i].fieldName == paths[j].fieldName {
+			return paths[i].option < paths[j].option
+		}
+		return paths[i].fieldName < paths[j].fieldName
+	})
+
+	var validPaths []string
+	var errs []*FieldPathError
+
+	for i, path := range paths {
+		if i > 0 && path.fieldName == paths[i-1].fieldName && path.option == paths[i-1].option {
+			continue // Tapped plans hold copies of the parent's paths
+		}
+
+		if path.err != nil {
+			errs = append(errs, path.err)
+			continue
+		}
+
+		fieldType, reason := resolveFieldPath(rootType, path.fieldName)
+		if reason != "" {
+			if validPaths == nil {
+				validPaths = listFieldPaths(rootType)
+			}
+
+			errs = append(errs, &FieldPathError{
+				FieldName:  path.fieldName,
+				Reason:     fmt.Sprintf("%v: %v", path.option, reason),
+				Suggestion: closestFieldPath(path.fieldName, validPaths),
+			})
+			continue
+		}
+
+		// The path resolved, so there is no closer path to suggest
+		if path.checkType != nil {
+			reason = path.checkType(fieldType)
+		}
+		if reason == "" && path.checkPath != nil {
+			reason = path.checkPath(rootType, path)
+		}
+
+		if reason != "" {
+			errs = append(errs, &FieldPathError{FieldName: path.fieldName, Reason: fmt.Sprintf("%v: %v", path.option, reason)})
+		}
+	}
+
+	errs = append(errs, p.tagErrors(rootType)...)
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Errors: errs}
+}
+
+// tagErrors reports the salem tags, and the validate tags when SetValidatorTags(...) is set,
+// that can't be used on their fields. The fields whose type has an invalid enum are also reported. The fields of each struct type are only reported once.
+func (p *Plan) tagErrors(rootType reflect.Type) []*FieldPathError {
+	type structField struct {
+		structType reflect.Type
+		index      int
+	}
+
+	var errs []*FieldPathError
+	reported := make(map[structField]bool)
+
+	walkFields(rootType, func(structType reflect.Type, index int, qualifiedName string) {
+		field := structType.Field(index)
+		if reported[structField{structType, index}] {
+			return
+		}
+		reported[structField{structType, index}] = true
+
+		directives, unsupported, tagKey := p.fieldDirectives(field)
+		for _, rule := range unsupported {
+			errs = append(errs, &FieldPathError{FieldName: qualifiedName, Reason: fmt.Sprintf("Unsupported %v rule '%v'", tagKey, rule)})
+		}
+
+		if _, err := buildTagRule(directives, field.Type); err != nil {
+			errs = append(errs, &FieldPathError{FieldName: qualifiedName, Reason: fmt.Sprintf("Invalid %v tag: %v", tagKey, err)})
+		}
+
+		valueTypes := []reflect.Type{tagValueType(field.Type)}
+		if field.Type.Kind() == reflect.Map {
+			valueTypes = append(valueTypes, tagValueType(field.Type.Key()))
+		}
+
+		for _, t := range valueTypes {
+			if err := p.enumError(t); err != nil {
+				errs = append(errs, &FieldPathError{FieldName: qualifiedName, Reason: fmt.Sprintf("RegisterEnum: %v", err)})
+				break
+			}
+		}
+	})
+
+	return errs
+}
+
+// configuredPaths lists the field paths used by the plan and the plans of tapped factories.
+//
+// visited guards against tapped plans that hold copies of their parent's constraints.
+func (p *Plan) configuredPaths(visited map[*Plan]bool) []configuredPath {
+	if visited[p] {
+		return nil
+	}
+	visited[p] = true
+
+	var paths []configuredPath
+
+	for _, err := range p.configErrors {
+		paths = append(paths, configuredPath{fieldName: err.FieldName, option: err.Reason, err: err})
+	}
+
+	for fieldName := range p.omittedFields {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: "Omit"})
+	}
+
+	for fieldName, setter := range p.ensuredFields {
+		switch {
+		case setter.tappedFactory != nil:
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "Ensure(Tap)", checkType: isKindCheck(reflect.Slice)})
+			for _, path := range setter.tappedFactory.plan.configuredPaths(visited) {
+				if path.scope == "" {
+					path.scope = fieldName
+				}
+				paths = append(paths, path)
+			}
+
+		case setter.fieldSequenceAction != nil:
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureSequence"})
+
+		default:
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "Ensure"})
+		}
+	}
+
+	for fieldName := range p.constrainedFields {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureConstraint"})
+	}
+
+	for fieldName, setter := range p.ensuredMapFields {
+		if setter.fieldSequenceKeyAction != nil {
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureMapKeySequence", checkType: isKindCheck(reflect.Map)})
+		}
+		if setter.fieldSequenceValueAction != nil {
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureMapValueSequence", checkType: isKindCheck(reflect.Map)})
+		}
+		if setter.keyPattern != nil {
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureMapKeyPattern", checkType: isStringMapKeyCheck})
+		}
+		if setter.valuePattern != nil {
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureMapValuePattern", checkType: isStringMapValueCheck})
+		}
+		if setter.keyChoice != nil {
+			paths = append(paths, configuredPath{fieldName: fieldName, option: setter.keyChoiceOption, checkType: choiceMapKeyCheck(setter.keyChoice)})
+		}
+	}
+
+	for fieldName := range p.patternFields {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsurePattern", checkType: isStringCheck})
+	}
+
+	for fieldName, choice := range p.choiceFields {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureOneOf", checkType: choiceCheck(choice)})
+	}
+
+	for fieldName := range p.distributionFields {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureDistribution", checkType: isNumericCheck})
+	}
+
+	for fieldName, option := range p.mapItemCountOptions {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: option, checkType: isKindCheck(reflect.Map)})
+	}
+
+	for fieldName := range p.timeRanges {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: "WithTimeRange", checkType: isTypeCheck(timeType)})
+	}
+
+	for fieldName := range p.monotonicTimes {
+		paths = append(paths, configuredPath{fieldName: fieldName, option: "WithMonotonicTime", checkType: isTypeCheck(timeType)})
+	}
+
+	for _, u := range p.uniqueFields {
+		for _, fieldName := range u.fieldNames {
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "EnsureUnique", checkPath: uniquePathCheck})
+		}
+	}
+
+	for _, derived := range p.derivedFields {
+		paths = append(paths, configuredPath{fieldName: derived.fieldName, option: "EnsureDerived"})
+	}
+
+	for fieldName, handler := range p.fieldHandlers {
+		if handler != nil {
+			paths = append(paths, configuredPath{fieldName: fieldName, option: "OnField"})
+		}
+	}
+
+	return paths
+}
+
+// isKindCheck creates a configuredPath.checkType that expects the field (or the value it points to) to be of kind k
+func isKindCheck(k reflect.Kind) func(reflect.Type) string {
+	return func(fieldType reflect.Type) string {
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType.Kind() == k {
+			return ""
+		}
+
+		return fmt.Sprintf("field is a %v not a %v", fieldType.Kind(), k)
+	}
+}
+
+// isTypeCheck creates a configuredPath.checkType that expects the field (or the value it points to) to be of type t
+func isTypeCheck(t reflect.Type) func(reflect.Type) string {
+	return func(fieldType reflect.Type) string {
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if fieldType == t {
+			return ""
+		}
+
+		return fmt.Sprintf("field is a %v not a %v", fieldType, t)
+	}
+}
+
+// isStringCheck is a configuredPath.checkType that expects the field to hold strings.
+// Pointers, and the elements of slices, arrays and maps are followed. E.g. []string or map[int]*string
+func isStringCheck(fieldType reflect.Type) string {
+	if t := tagValueType(fieldType); t.Kind() != reflect.String {
+		return fmt.Sprintf("field holds %v values not strings", t.Kind())
+	}
+
+	return ""
+}
+
+// isNumericCheck is a configuredPath.checkType that expects the field to hold numbers. See isStringCheck
+func isNumericCheck(fieldType reflect.Type) string {
+	if t := tagValueType(fieldType); !isNumericType(t) {
+		return fmt.Sprintf("field holds %v values not numbers", t.Kind())
+	}
+
+	return ""
+}
+
+// isStringMapKeyCheck is a configuredPath.checkType that expects a map with string keys
+func isStringMapKeyCheck(fieldType reflect.Type) string {
+	if reason := isKindCheck(reflect.Map)(fieldType); reason != "" {
+		return reason
+	}
+
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	if fieldType.Key().Kind() != reflect.String {
+		return fmt.Sprintf("map keys are %v not strings", fieldType.Key().Kind())
+	}
+
+	return ""
+}
+
+// isStringMapValueCheck is a configuredPath.checkType that expects a map with string values
+func isStringMapValueCheck(fieldType reflect.Type) string {
+	if reason := isKindCheck(reflect.Map)(fieldType); reason != "" {
+		return reason
+	}
+
+	return isStringCheck(fieldType)
+}
+
+// choiceCheck creates a configuredPath.checkType that expects the values of the choice to fit the field.
+// Pointers, and the elements of slices, arrays and maps are followed. E.g. "active" fits a []*Status field
+func choiceCheck(choice *valueChoice) func(reflect.Type) string {
+	return func(fieldType reflect.Type) string {
+		for t := fieldType; ; t = t.Elem() {
+			if t.Kind() != reflect.Map && choice.fits(t) {
+				return ""
+			}
+
+			if k := t.Kind(); k != reflect.Ptr && k != reflect.Slice && k != reflect.Array && k != reflect.Map {
+				return fmt.Sprintf("the values don't fit the %v field", fieldType)
+			}
+		}
+	}
+}
+
+// choiceMapKeyCheck creates a configuredPath.checkType that expects a map with keys that fit the values of the choice
+func choiceMapKeyCheck(choice *valueChoice) func(reflect.Type) string {
+	return func(fieldType reflect.Type) string {
+		if reason := isKindCheck(reflect.Map)(fieldType); reason != "" {
+			return reason
+		}
+
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		if !choice.fits(fieldType.Key()) {
+			return fmt.Sprintf("the values don't fit the %v keys", fieldType.Key())
+		}
+
+		return ""
+	}
+}
+
+// uniquePathCheck is a configuredPath.checkPath that expects the path not to cross a slice, array or map.
+// Each item has one value for the path, so the elements can only be made unique by a tapped factory.
+// E.g. Ensure("Friends", Tap().EnsureUnique("Friends.Handle"))
+func uniquePathCheck(rootType reflect.Type, path configuredPath) string {
+	currentType := rootType
+	segments := strings.Split(path.fieldName, ".")
+
+	for i, segment := range segments[:len(segments)-1] {
+		name, indexes, _ := splitElementIndexes(segment)
+		field, _ := exportedField(elementStructType(currentType), name)
+
+		currentType = field.Type
+		for range indexes {
+			currentType = currentType.Elem()
+		}
+		for currentType.Kind() == reflect.Ptr {
+			currentType = currentType.Elem()
+		}
+
+		prefix := strings.Join(segments[:i+1], ".")
+		if k := currentType.Kind(); (k == reflect.Slice || k == reflect.Array || k == reflect.Map) && prefix != path.scope {
+			return fmt.Sprintf("'%v' is a %v so the field has a value per element. Use EnsureUnique(...) on a tapped factory for '%v' instead", prefix, k, prefix)
+		}
+	}
+
+	return ""
+}
+
+// resolveFieldPath walks rootType using the dot separated fieldName.
+// It returns the type of the field, or the reason the path can't be resolved.
+func resolveFieldPath(rootType reflect.Type, fieldName string) (reflect.Type, string) {
+	currentType := rootType
+
+	for _, segment := range strings.Split(fieldName, ".") {
+		name, indexes, ok := splitElementIndexes(segment)
+		if !ok {
+			return nil, fmt.Sprintf("'%v' has an invalid element index", segment)
+		}
+
+		structType := elementStructType(currentType)
+		if structType == nil {
+			return nil, fmt.Sprintf("'%v' can't be resolved since %v has no fields", name, currentType)
+		}
+
+		field, ok := exportedField(structType, name)
+		if !ok {
+			return nil, fmt.Sprintf("'%v' is not an exported field of %v", name, structType)
+		}
+
+		currentType = field.Type
+		for _, index := range indexes { // E.g. Hash[0] or Matrix[1][2]
+			if currentType.Kind() != reflect.Array {
+				return nil, fmt.Sprintf("'%v' is a %v. Only the elements of arrays can be configured", segment, currentType.Kind())
+			}
+			if index >= currentType.Len() {
+				return nil, fmt.Sprintf("'%v' is out of range for %v", segment, currentType)
+			}
+
+			currentType = currentType.Elem()
+		}
+	}
+
+	return currentType, ""
+}
+
+// splitElementIndexes splits a path segment such as Matrix[1][2] into its name and element indexes
+func splitElementIndexes(segment string) (string, []int, bool) {
+	name, rest, hasIndexes := strings.Cut(segment, "[")
+	if !hasIndexes {
+		return segment, nil, true
+	}
+
+	var indexes []int
+	for _, part := range strings.Split("["+rest, "]") {
+		if part == "" {
+			continue
+		}
+
+		index, err := strconv.Atoi(strings.TrimPrefix(part, "["))
+		if err != nil || !strings.HasPrefix(part, "[") || index < 0 {
+			return name, nil, false
+		}
+		indexes = append(indexes, index)
+	}
+
+	return name, indexes, true
+}
+
+// elementStructType returns the struct that is mocked for the fields of type t.
+// Pointers, slices, arrays and maps are followed to the struct they hold.
+func elementStructType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+
+		case reflect.Struct:
+			return t
+
+		default:
+			return nil
+		}
+	}
+}
+
+// exportedField looks up the exported field that the plan would mock
+func exportedField(structType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+
+		if field.Name == name && field.PkgPath == "" {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// listFieldPaths returns the field paths that can be configured for rootType
+func listFieldPaths(rootType reflect.Type) []string {
+	var paths []string
+
+	walkFields(rootType, func(_ reflect.Type, _ int, qualifiedName string) {
+		paths = append(paths, qualifiedName)
+	})
+
+	return paths
+}
+
+// walkFields calls fn with each exported field that the plan would mock for rootType
+func walkFields(rootType reflect.Type, fn func(structType reflect.Type, index int, qualifiedName string)) {
+	var walk func(t reflect.Type, parentName string, depth int)
+
+	walk = func(t reflect.Type, parentName string, depth int) {
+		structType := elementStructType(t)
+		if structType == nil || depth > maxFieldPathDepth {
+			return
+		}
+
+		for i := 0; i < structType.NumField(); i++ {
+			field := structType.Field(i)
+			if field.PkgPath != "" {
+				continue // Skip private fields
+			}
+
+			qualifiedName := distinctFileName(parentName, field.Name)
+			fn(structType, i, qualifiedName)
+
+			walk(field.Type, qualifiedName, depth+1)
+		}
+	}
+
+	walk(rootType, "", 0)
+}
+
+// closestFieldPath returns the path in validPaths that is closest to fieldName.
+// An empty string is returned when none of the paths are close enough to be a likely typo.
+func closestFieldPath(fieldName string, validPaths []string) string {
+	maxDistance := len(fieldName) / 3
+	if maxDistance < 3 {
+		maxDistance = 3
+	}
+
+	var closest string
+	closestDistance := maxDistance + 1
+
+	for _, path := range validPaths {
+		distance := levenshteinDistance(strings.ToLower(fieldName), strings.ToLower(path))
+		if distance < closestDistance {
+			closest = path
+			closestDistance = distance
+		}
+	}
+
+	return closest
+}
+
+// levenshteinDistance is the number of single character edits needed to change a into b
+func levenshteinDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func minInt(first int, others ...int) int {
+	result := first
+	for _, v := range others {
+		if v < result {
+			result = v
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,316 @@
+// Copyright 2021 Harold Campbell. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+package salem
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// validatorTagKey is the struct tag used by go-playground/validator. E.g. `validate:"required,min=3"`
+const validatorTagKey = "validate"
+
+// defaultItemSpan matches the [n, n+10) range of WithMinItems(...)
+const defaultItemSpan = 9
+
+// validatorCharsets are the validator rules that limit the characters of a string
+var validatorCharsets = map[string]string{
+	"alpha":     "a-zA-Z",
+	"alphanum":  "a-zA-Z0-9",
+	"numeric":   "0-9",
+	"number":    "0-9",
+	"lowercase": "a-z",
+	"uppercase": "A-Z",
+}
+
+// validatorGenerators are the validator rules that have a named generator
+var validatorGenerators = map[string]string{
+	"email":    "email",
+	"url":      "url",
+	"uri":      "url",
+	"http_url": "url",
+	"uuid":     "uuid",
+	"uuid4":    "uuid",
+}
+
+// namedGeneratorLengths are the [min, max] lengths of the strings made by the named generators
+var namedGeneratorLengths = map[string][2]float64{
+	"email": {11, 25}, // word@word.com
+	"url":   {19, 33}, // https://word.com/word
+	"uuid":  {36, 36},
+}
+
+// fieldDirectives returns the field's directives and the tag they were read from.
+//
+// The validate tag is only used when SetValidatorTags(...) is set and the field has no salem tag.
+// The validator rules that can't be converted are also returned.
+func (p *Plan) fieldDirectives(field reflect.StructField) ([]tagDirective, []string, string) {
+	if tag, ok := field.Tag.Lookup(salemTagKey); ok || !p.useValidatorTags {
+		return parseSalemTag(tag), nil, salemTagKey
+	}
+
+	directives, unsupported := validatorDirectives(field.Tag.Get(validatorTagKey), field.Type)
+
+	return directives, unsupported, validatorTagKey
+}
+
+// SetValidatorTags converts the go-playground/validator tags of the fields into salem tag directives
+func (p *Plan) SetValidatorTags(useValidatorTags bool) {
+	p.useValidatorTags = useValidatorTags
+}
+
+// validatorDirectives converts the rules of a validate tag into salem tag directives.
+// The rules that can't be converted are returned as unsupported.
+func validatorDirectives(tag string, fieldType reflect.Type) ([]tagDirective, []string) {
+	t := fieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	isCollection := t.Kind() == reflect.Slice || t.Kind() == reflect.Map || t.Kind() == reflect.Array
+	isInt := reflect.Int <= t.Kind() && t.Kind() <= reflect.Uintptr
+
+	var directives []tagDirective
+	var unsupported []string
+	values := make(map[string]string)
+	sources := make(map[string]string) // The rule each bound was read from. E.g. max => lte=10
+	required := false
+
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch {
+		case name == "" || name == "omitempty":
+			// Nothing to generate
+
+		case name == "required":
+			required = true
+
+		case name == "min" || name == "gte":
+			values["min"], sources["min"] = value, rule
+
+		case name == "max" || name == "lte":
+			values["max"], sources["max"] = value, rule
+
+		case name == "len":
+			values["len"], sources["len"] = value, rule
+
+		case (name == "gt" || name == "lt") && isInt:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				unsupported = append(unsupported, rule)
+				continue
+			}
+
+			if name == "gt" {
+				values["min"], sources["min"] = strconv.FormatInt(n+1, 10), rule
+			} else {
+				values["max"], sources["max"] = strconv.FormatInt(n-1, 10), rule
+			}
+
+		case name == "oneof":
+			values["oneof"] = strings.Join(strings.Fields(value), "|")
+
+		case validatorGenerators[name] != "":
+			values["gen"] = validatorGenerators[name]
+
+		case validatorCharsets[name] != "":
+			values["charset"] = validatorCharsets[name]
+
+		default: // E.g. dive, or rules such as email|url
+			unsupported = append(unsupported, rule)
+		}
+
+		if name == "dive" {
+			// The remaining rules apply to the elements, which aren't generated from the tag
+			for _, elemRule := range rules[i+1:] {
+				if elemRule = strings.TrimSpace(elemRule); elemRule != "" {
+					unsupported = append(unsupported, elemRule)
+				}
+			}
+			break
+		}
+	}
+
+	if isCollection {
+		return collectionDirectives(values), unsupported
+	}
+
+	if values["gen"] != "" || values["oneof"] != "" {
+		unsupported = append(unsupported, applyValueBounds(values, sources, t)...)
+	}
+
+	if charset, ok := values["charset"]; ok {
+		values = charsetDirectives(charset, values)
+	}
+
+	if required {
+		addRequiredDirectives(values, t)
+	}
+
+	for _, name := range []string{"oneof", "regex", "gen", "len", "min", "max"} {
+		if value, ok := values[name]; ok {
+			directives = append(directives, tagDirective{name: name, value: value})
+		}
+	}
+
+	return directives, unsupported
+}
+
+// applyValueBounds applies the len=, min= and max= rules to the values of a gen= or oneof= rule, then removes them.
+//
+// The oneof= values outside the bounds are dropped. The bound rules are returned as unsupported when
+// no oneof= value is within them, or when the named generator can make values outside them. E.g. required,email,max=10
+func applyValueBounds(values map[string]string, sources map[string]string, t reflect.Type) []string {
+	var bounds []string
+	for _, name := range []string{"len", "min", "max"} {
+		if rule, ok := sources[name]; ok {
+			bounds = append(bounds, rule)
+		}
+	}
+
+	min, max, ok := valueBounds(values)
+	delete(values, "len")
+	delete(values, "min")
+	delete(values, "max")
+
+	if len(bounds) == 0 || t.Kind() == reflect.Bool {
+		return nil
+	}
+	if !ok {
+		return bounds
+	}
+
+	// measure returns the value compared to the bounds. The length of strings, otherwise the number
+	measure := func(value string) (float64, bool) {
+		if t.Kind() == reflect.String {
+			return float64(utf8.RuneCountInString(value)), true
+		}
+
+		n, err := strconv.ParseFloat(value, 64)
+		return n, err == nil
+	}
+
+	if name, ok := values["gen"]; ok {
+		lengths, known := namedGeneratorLengths[name]
+		if !known || t.Kind() != reflect.String || lengths[0] < min || lengths[1] > max {
+			return bounds
+		}
+		return nil
+	}
+
+	var options []string
+	for _, option := range strings.Split(values["oneof"], "|") {
+		if n, ok := measure(option); !ok || (min <= n && n <= max) {
+			options = append(options, option) // Values that can't be measured are reported by oneOfGenerator(...)
+		}
+	}
+
+	if len(options) == 0 {
+		return bounds
+	}
+
+	values["oneof"] = strings.Join(options, "|")
+	return nil
+}
+
+// valueBounds returns the [min, max] range set by the len=, min= and max= values. ok is false when one isn't a number
+func valueBounds(values map[string]string) (min float64, max float64, ok bool) {
+	min, max = math.Inf(-1), math.Inf(1)
+
+	parse := func(name string, bound *float64) bool {
+		value, found := values[name]
+		if !found {
+			return true
+		}
+
+		n, err := strconv.ParseFloat(value, 64)
+		*bound = n
+		return err == nil
+	}
+
+	if _, hasLen := values["len"]; hasLen {
+		ok = parse("len", &min)
+		max = min
+		return min, max, ok
+	}
+
+	ok = parse("min", &min) && parse("max", &max)
+	return min, max, ok
+}
+
+// collectionDirectives converts the length rules of a slice or map into an items= directive
+func collectionDirectives(values map[string]string) []tagDirective {
+	if n, ok := values["len"]; ok {
+		return []tagDirective{{name: "items", value: n}}
+	}
+
+	min, hasMin := values["min"]
+	max, hasMax := values["max"]
+	switch {
+	case hasMin && !hasMax:
+		n, _ := strconv.Atoi(min)
+		max = strconv.Itoa(n + defaultItemSpan)
+
+	case hasMax && !hasMin:
+		min = "0"
+
+	case !hasMin && !hasMax:
+		return nil
+	}
+
+	return []tagDirective{{name: "items", value: fmt.Sprintf("%v..%v", min, max)}}
+}
+
+// charsetDirectives converts a charset rule (e.g. alpha) and the string's length into a regex= directive
+func charsetDirectives(charset string, values map[string]string) map[string]string {
+	min, max := values["min"], values["max"]
+	if n, ok := values["len"]; ok {
+		min, max = n, n
+	}
+
+	if min == "" {
+		min = "1"
+	}
+	if max == "" {
+		n, _ := strconv.Atoi(min)
+		max = strconv.Itoa(n + defaultStringSpan)
+	}
+
+	result := map[string]string{"regex": fmt.Sprintf("[%v]{%v,%v}", charset, min, max)}
+	for _, name := range []string{"oneof", "gen"} { // Reported as a conflict by buildTagRule(...)
+		if value, ok := values[name]; ok {
+			result[name] = value
+		}
+	}
+
+	return result
+}
+
+// addRequiredDirectives makes sure that the generated value isn't the zero value of t
+func addRequiredDirectives(values map[string]string, t reflect.Type) {
+	for _, name := range []string{"oneof", "regex", "gen", "len", "min"} {
+		if _, ok := values[name]; ok {
+			return
+		}
+	}
+
+	switch {
+	case t.Kind() == reflect.Bool:
+		values["oneof"] = "true"
+
+	case t.Kind() == reflect.String:
+		values["min"] = "1"
+
+	case isPrimitiveKind(t):
+		if _, hasMax := values["max"]; !hasMax {
+			values["min"] = "1"
+		}
+	}
+}